@@ -0,0 +1,281 @@
+// Copyright 2013 Oscar Campos <oscar.campos@member.fsf.org>
+// See LICENSE file for details.
+
+package liveodds
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrUnknownBetStatus is returned when a betstatus attribute is not one of
+// the values defined by the BetRadar LiveOdds protocol
+var ErrUnknownBetStatus = errors.New("liveodds: unknown bet status")
+
+// BetStatus is the betting state of a match as set in its betstatus
+// attribute
+type BetStatus uint8
+
+const (
+	BetStatusUnknown BetStatus = iota
+	BetStarted
+	BetStopped
+)
+
+// ParseBetStatus returns the BetStatus for the given betstatus attribute
+// value, values that are not part of the protocol return BetStatusUnknown
+// and an error wrapping ErrUnknownBetStatus
+func ParseBetStatus(status string) (BetStatus, error) {
+	switch status {
+	case "started":
+		return BetStarted, nil
+	case "stopped":
+		return BetStopped, nil
+	}
+	return BetStatusUnknown, fmt.Errorf("%w %q", ErrUnknownBetStatus, status)
+}
+
+// String returns the status as it is written in the betstatus attribute
+func (s BetStatus) String() string {
+	switch s {
+	case BetStatusUnknown:
+		return "unknown"
+	case BetStarted:
+		return "started"
+	case BetStopped:
+		return "stopped"
+	}
+	return fmt.Sprintf("BetStatus(%d)", s)
+}
+
+// SuspendReason is why betting was suspended for a match
+type SuspendReason uint8
+
+const (
+	// SuspendBetStop means that a betstop message was received
+	SuspendBetStop SuspendReason = iota + 1
+	// SuspendBetStatus means that the betstatus attribute of another
+	// message, like a change or a reply to a request, said stopped
+	SuspendBetStatus
+)
+
+func (r SuspendReason) String() string {
+	switch r {
+	case SuspendBetStop:
+		return "betstop"
+	case SuspendBetStatus:
+		return "betstatus"
+	}
+	return fmt.Sprintf("SuspendReason(%d)", r)
+}
+
+// Suspension records when and why betting was suspended for a match
+type Suspension struct {
+	Time        time.Time // Epoch of the message that suspended it
+	Reason      SuspendReason
+	Message     MessageStatus // status of the message that suspended it
+	MatchStatus string        // status of the match then, like "paused" or "ended"
+	MsgNR       uint32
+}
+
+// BetStatusEventKind is the kind of suspicious transition found in the bet
+// status of a match
+type BetStatusEventKind uint8
+
+const (
+	// BetStatusRepeated means a betstart while betting was started or a
+	// betstop while it was stopped
+	BetStatusRepeated BetStatusEventKind = iota + 1
+	// BetStatusConflict means a betstart or betstop message whose match has
+	// the opposite betstatus attribute, or a reply to a request that says
+	// started but is older than the message that stopped betting
+	BetStatusConflict
+	// BetStatusUnannounced means that the betstatus attribute of a message
+	// other than betstart or betstop changed the bet status. A stop is
+	// applied but a start is ignored, only betstart opens betting again.
+	BetStatusUnannounced
+	// BetStatusActiveOdds means a change message with active odds while
+	// betting is stopped
+	BetStatusActiveOdds
+)
+
+func (k BetStatusEventKind) String() string {
+	switch k {
+	case BetStatusRepeated:
+		return "repeated"
+	case BetStatusConflict:
+		return "conflict"
+	case BetStatusUnannounced:
+		return "unannounced"
+	case BetStatusActiveOdds:
+		return "activeodds"
+	}
+	return fmt.Sprintf("BetStatusEventKind(%d)", k)
+}
+
+// BetStatusEvent reports a suspicious transition in the bet status of a
+// match, Status is the bet status once the message was applied
+type BetStatusEvent struct {
+	Kind    BetStatusEventKind
+	MatchID uint32
+	Message MessageStatus
+	Status  BetStatus
+	MsgNR   uint32
+}
+
+type betState struct {
+	status     BetStatus
+	suspension Suspension
+}
+
+// BetStatusTracker follows the bet status of every match, moved by the
+// betstart and betstop messages, and reports the transitions that do not
+// make sense. The first message seen for a match sets its status from the
+// betstatus attribute, as do replies to requests like currentodds, so the
+// tracker should be fed those replies after connecting. A reply only opens
+// betting again when it is newer than the message that stopped it.
+// It is safe to use from several goroutines.
+type BetStatusTracker struct {
+	mu      sync.RWMutex
+	matches map[uint32]*betState
+}
+
+// NewBetStatusTracker creates a BetStatusTracker with no matches
+func NewBetStatusTracker() *BetStatusTracker {
+	return &BetStatusTracker{matches: make(map[uint32]*betState)}
+}
+
+// Apply moves the bet status of every match in msg and returns the
+// suspicious transitions found
+func (t *BetStatusTracker) Apply(msg *BetRadarLiveOdds) []BetStatusEvent {
+	kind := msg.Kind()
+	reply := msg.ReplyType != ""
+	timestamp := msg.Epoch()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var events []BetStatusEvent
+	for i := range msg.Matches {
+		m := &msg.Matches[i]
+		attr, _ := ParseBetStatus(m.BetStatus)
+		state, ok := t.matches[m.MatchID]
+		if !ok {
+			state = &betState{}
+			t.matches[m.MatchID] = state
+		}
+
+		report := func(k BetStatusEventKind) {
+			events = append(events, BetStatusEvent{k, m.MatchID, kind, state.status, m.MsgNR})
+		}
+		stop := func(reason SuspendReason) {
+			state.status = BetStopped
+			state.suspension = Suspension{timestamp, reason, kind, m.Status, m.MsgNR}
+		}
+
+		switch {
+		case kind == StatusBetStart:
+			repeated := state.status == BetStarted
+			state.status = BetStarted
+			state.suspension = Suspension{}
+			if attr == BetStopped {
+				report(BetStatusConflict)
+			}
+			if repeated {
+				report(BetStatusRepeated)
+			}
+		case kind == StatusBetStop:
+			if state.status == BetStopped {
+				report(BetStatusRepeated)
+			} else {
+				stop(SuspendBetStop)
+			}
+			if attr == BetStarted {
+				report(BetStatusConflict)
+			}
+		case attr == BetStatusUnknown || attr == state.status:
+		case reply && attr == BetStarted && state.status == BetStopped &&
+			!newerThan(m.MsgNR, timestamp, &state.suspension):
+			// a snapshot taken before betting was stopped
+			report(BetStatusConflict)
+		case reply || state.status == BetStatusUnknown:
+			// a snapshot of the match, nothing to compare it with
+			if attr == BetStopped {
+				stop(SuspendBetStatus)
+			} else {
+				state.status = BetStarted
+				state.suspension = Suspension{}
+			}
+		case attr == BetStopped:
+			stop(SuspendBetStatus)
+			report(BetStatusUnannounced)
+		default:
+			report(BetStatusUnannounced)
+		}
+
+		if kind == StatusChange && state.status == BetStopped && hasActiveOdds(m.Odds) {
+			report(BetStatusActiveOdds)
+		}
+	}
+	return events
+}
+
+// newerThan reports whether a message with the given msgnr and timestamp
+// came after the one that suspended betting. The msgnr is compared when
+// both have one, replies to requests may not, and the timestamp otherwise.
+func newerThan(msgnr uint32, timestamp time.Time, s *Suspension) bool {
+	if msgnr != 0 && s.MsgNR != 0 {
+		return msgnr > s.MsgNR
+	}
+	return timestamp.After(s.Time)
+}
+
+func hasActiveOdds(odds []Odd) bool {
+	for i := range odds {
+		if odds[i].Active {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOpenForBetting reports whether betting is started for the given match,
+// it is false for matches that were never seen
+func (t *BetStatusTracker) IsOpenForBetting(matchID uint32) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	state, ok := t.matches[matchID]
+	return ok && state.status == BetStarted
+}
+
+// Status returns the bet status of the given match
+func (t *BetStatusTracker) Status(matchID uint32) (BetStatus, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	state, ok := t.matches[matchID]
+	if !ok {
+		return BetStatusUnknown, false
+	}
+	return state.status, true
+}
+
+// Suspension returns when and why betting was suspended for the given
+// match, ok is false when it is not suspended
+func (t *BetStatusTracker) Suspension(matchID uint32) (Suspension, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	state, ok := t.matches[matchID]
+	if !ok || state.status != BetStopped {
+		return Suspension{}, false
+	}
+	return state.suspension, true
+}
+
+// Forget stops tracking the given match
+func (t *BetStatusTracker) Forget(matchID uint32) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.matches, matchID)
+}
@@ -0,0 +1,166 @@
+package liveodds
+
+import (
+	"errors"
+	"testing"
+)
+
+// betMessage builds a message with the given status for match 1
+func betMessage(status, betstatus string, msgnr uint32) *BetRadarLiveOdds {
+	return &BetRadarLiveOdds{
+		Status:    status,
+		Timestamp: 1383789032026 + int64(msgnr),
+		Matches:   []Match{{MatchID: 1, MsgNR: msgnr, BetStatus: betstatus, Status: "1p"}},
+	}
+}
+
+func TestParseBetStatus(t *testing.T) {
+	started, _ := ParseBetStatus("started")
+	stopped, _ := ParseBetStatus("stopped")
+	unknown, err := ParseBetStatus("stoped")
+
+	var xmlTests = []xmlTest{
+		{started, BetStarted},
+		{stopped, BetStopped},
+		{unknown, BetStatusUnknown},
+		{errors.Is(err, ErrUnknownBetStatus), true},
+		{BetStopped.String(), "stopped"},
+		{SuspendBetStop.String(), "betstop"},
+		{BetStatusActiveOdds.String(), "activeodds"},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestParseBetStatus", tt.expected, tt.n)
+		}
+	}
+}
+
+func TestBetStatusTracker(t *testing.T) {
+	tracker := NewBetStatusTracker()
+	apply := func(status, betstatus string, msgnr uint32) []BetStatusEvent {
+		return tracker.Apply(betMessage(status, betstatus, msgnr))
+	}
+
+	var xmlTests = []xmlTest{
+		{tracker.IsOpenForBetting(1), false},
+		{len(apply("betstart", "started", 1)), 0},
+		{tracker.IsOpenForBetting(1), true},
+		{len(apply("change", "started", 2)), 0},
+		{len(apply("betstop", "stopped", 3)), 0},
+		{tracker.IsOpenForBetting(1), false},
+		{apply("betstop", "stopped", 4)[0], BetStatusEvent{BetStatusRepeated, 1, StatusBetStop, BetStopped, 4}},
+		{apply("change", "started", 5)[0], BetStatusEvent{BetStatusUnannounced, 1, StatusChange, BetStopped, 5}},
+		{tracker.IsOpenForBetting(1), false},
+		{len(apply("betstart", "started", 6)), 0},
+		{apply("betstart", "started", 7)[0], BetStatusEvent{BetStatusRepeated, 1, StatusBetStart, BetStarted, 7}},
+		{apply("score", "stopped", 8)[0], BetStatusEvent{BetStatusUnannounced, 1, StatusScore, BetStopped, 8}},
+		{tracker.IsOpenForBetting(1), false},
+		{apply("betstart", "stopped", 9)[0], BetStatusEvent{BetStatusConflict, 1, StatusBetStart, BetStarted, 9}},
+		{tracker.IsOpenForBetting(1), true},
+		{len(apply("alive", "", 9)), 0},
+		{tracker.IsOpenForBetting(1), true},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestBetStatusTracker", tt.expected, tt.n)
+		}
+	}
+}
+
+func TestBetStatusSuspension(t *testing.T) {
+	tracker := NewBetStatusTracker()
+	tracker.Apply(betMessage("betstart", "started", 1))
+	if _, ok := tracker.Suspension(1); ok {
+		t.Error("match 1 should not be suspended")
+	}
+
+	stop := betMessage("betstop", "stopped", 2)
+	stop.Matches[0].Status = "paused"
+	tracker.Apply(stop)
+	tracker.Apply(betMessage("betstop", "stopped", 3))
+
+	suspension, ok := tracker.Suspension(1)
+	expected := Suspension{stop.Epoch(), SuspendBetStop, StatusBetStop, "paused", 2}
+	if !ok || suspension != expected {
+		t.Errorf(failed_msg, "TestBetStatusSuspension", expected, suspension)
+	}
+
+	tracker.Forget(1)
+	if _, ok := tracker.Status(1); ok {
+		t.Error("match 1 should not be tracked anymore")
+	}
+}
+
+func TestBetStatusReplies(t *testing.T) {
+	tracker := NewBetStatusTracker()
+	tracker.Apply(betMessage("betstop", "stopped", 5))
+	reply := func(betstatus string, msgnr uint32, timestamp int64) []BetStatusEvent {
+		msg := betMessage("change", betstatus, msgnr)
+		msg.ReplyType = "currentodds"
+		msg.Timestamp = timestamp
+		return tracker.Apply(msg)
+	}
+	stop := betMessage("betstop", "stopped", 5)
+
+	// replies older than the betstop, by msgnr or by timestamp without one
+	byMsgNR := reply("started", 4, stop.Timestamp+10)
+	byTimestamp := reply("started", 0, stop.Timestamp-10)
+	closed := tracker.IsOpenForBetting(1)
+	suspension, _ := tracker.Suspension(1)
+
+	var xmlTests = []xmlTest{
+		{len(byMsgNR), 1},
+		{byMsgNR[0], BetStatusEvent{BetStatusConflict, 1, StatusChange, BetStopped, 4}},
+		{len(byTimestamp), 1},
+		{byTimestamp[0].Kind, BetStatusConflict},
+		{closed, false},
+		{suspension.Reason, SuspendBetStop},
+		{len(reply("started", 0, stop.Timestamp+10)), 0},
+		{tracker.IsOpenForBetting(1), true},
+		{len(reply("stopped", 0, stop.Timestamp+20)), 0},
+	}
+	suspension, _ = tracker.Suspension(1)
+	xmlTests = append(xmlTests, xmlTest{suspension.Reason, SuspendBetStatus})
+	xmlTests = append(xmlTests, xmlTest{len(reply("started", 6, stop.Timestamp+30)), 0})
+	xmlTests = append(xmlTests, xmlTest{tracker.IsOpenForBetting(1), true})
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestBetStatusReplies", tt.expected, tt.n)
+		}
+	}
+}
+
+func TestBetStatusFixtures(t *testing.T) {
+	tracker := NewBetStatusTracker()
+	change := LoadXMLFixture("fixtures/change.xml")
+	betstart := LoadXMLFixture("fixtures/betstart.xml")
+	betstop := LoadXMLFixture("fixtures/betstop.xml")
+
+	events := tracker.Apply(&change)
+	expected := BetStatusEvent{BetStatusActiveOdds, 867278, StatusChange, BetStopped, 2}
+	if len(events) != 1 || events[0] != expected {
+		t.Errorf(failed_msg, "TestBetStatusFixtures", expected, events)
+	}
+
+	tracker.Apply(&betstart)
+	open := tracker.IsOpenForBetting(935449)
+	tracker.Apply(&betstop)
+	suspension, _ := tracker.Suspension(935449)
+
+	var xmlTests = []xmlTest{
+		{open, true},
+		{tracker.IsOpenForBetting(935449), false},
+		{suspension.MatchStatus, "ended"},
+		{suspension.Time, betstop.Epoch()},
+		{suspension.MsgNR, uint32(51)},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestBetStatusFixtures", tt.expected, tt.n)
+		}
+	}
+}
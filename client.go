@@ -0,0 +1,599 @@
+// Copyright 2013 Oscar Campos <oscar.campos@member.fsf.org>
+// See LICENSE file for details.
+
+package liveodds
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Default values used for the zero fields of Config
+const (
+	DefaultAliveTimeout = 30 * time.Second
+	DefaultMinBackoff   = time.Second
+	DefaultMaxBackoff   = time.Minute
+
+	DefaultRequestTimeout = 30 * time.Second
+
+	DefaultMessagesBuffer = 64
+	DefaultErrorsBuffer   = 16
+)
+
+// ErrClosed is returned by the Client methods once Close has been called
+var ErrClosed = errors.New("liveodds: client closed")
+
+// ErrAliveTimeout is the reason of a reconnection when the server does not
+// send any alive message in Config.AliveTimeout
+var ErrAliveTimeout = errors.New("liveodds: alive timeout")
+
+// errNotLogin is used when the first message from the server is not the
+// reply to the login
+var errNotLogin = errors.New("liveodds: expected a BookmakerStatus login reply")
+
+// Config contains the settings that a Client needs to connect to the
+// BetRadar LiveOdds server
+type Config struct {
+	// Addr is the host:port of the LiveOdds server
+	Addr string
+
+	// BookmakerID and Key are the credentials given by BetRadar
+	BookmakerID uint16
+	Key         string
+
+	// TLSConfig is used to wrap the connection in TLS, if it is nil a
+	// plain TCP connection is used instead
+	TLSConfig *tls.Config
+
+	// DialTimeout limits the time spent connecting and doing the login,
+	// zero means no limit other than the one of the given context
+	DialTimeout time.Duration
+
+	// AliveTimeout is the time without alive messages after which the
+	// connection is considered dead, DefaultAliveTimeout if zero
+	AliveTimeout time.Duration
+
+	// MinBackoff and MaxBackoff bound the exponential backoff between
+	// reconnection attempts, DefaultMinBackoff and DefaultMaxBackoff if zero
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// RequestTimeout is the longest time that Request waits for a reply,
+	// DefaultRequestTimeout if zero
+	RequestTimeout time.Duration
+
+	// OnSequenceEvent, if not nil, is called from the reading goroutine for
+	// every gap, duplicate or reset found in the msgnr of a match
+	OnSequenceEvent func(SequenceEvent)
+
+	// RequestOddsOnGap makes the client send a current odds request for a
+	// match as soon as a gap is found in its msgnr, as a missed change
+	// means that the odds that we have are stale
+	RequestOddsOnGap bool
+
+	// MessagesBuffer is the number of messages that the client keeps while
+	// they are not read with Next or Messages, DefaultMessagesBuffer if
+	// zero. SlowConsumer decides what happens once it is full, note that
+	// with BlockOnFull the replies to Request are not read either.
+	MessagesBuffer int
+	SlowConsumer   SlowConsumerPolicy
+
+	// ErrorsBuffer is the number of errors kept for Errors, the errors
+	// that do not fit are discarded. DefaultErrorsBuffer if zero.
+	ErrorsBuffer int
+}
+
+func (config Config) withDefaults() Config {
+	if config.AliveTimeout == 0 {
+		config.AliveTimeout = DefaultAliveTimeout
+	}
+	if config.MinBackoff == 0 {
+		config.MinBackoff = DefaultMinBackoff
+	}
+	if config.MaxBackoff == 0 {
+		config.MaxBackoff = DefaultMaxBackoff
+	}
+	if config.MaxBackoff < config.MinBackoff {
+		config.MaxBackoff = config.MinBackoff
+	}
+	if config.RequestTimeout == 0 {
+		config.RequestTimeout = DefaultRequestTimeout
+	}
+	if config.MessagesBuffer <= 0 {
+		config.MessagesBuffer = DefaultMessagesBuffer
+	}
+	if config.ErrorsBuffer <= 0 {
+		config.ErrorsBuffer = DefaultErrorsBuffer
+	}
+	return config
+}
+
+// Client is a connection to the BetRadar LiveOdds XML feed. It logs in on
+// Dial and then streams the BetRadarLiveOdds messages sent by the server
+// that can be read calling Next or from the Messages channel.
+//
+// When the connection is lost, or no alive message arrives in time, the
+// Client reconnects with exponential backoff, logs in again and registers
+// again every match that was registered with Register.
+type Client struct {
+	config   Config
+	sequence *SequenceTracker
+
+	queue    *messageQueue
+	errs     chan error
+	quitOnce sync.Once
+	done     chan struct{}
+
+	wmu  sync.Mutex // serializes writes and guards conn
+	conn net.Conn
+
+	mu        sync.Mutex
+	err       error
+	closed    bool
+	matches   map[uint32]bool
+	lastAlive time.Time
+	replyNr   uint32
+	pending   map[uint32]chan Message // waiting for a reply
+}
+
+// Dial connects to the LiveOdds server in config.Addr, sends the login
+// message and waits for the login reply. The returned Client is already
+// receiving messages from the feed.
+func Dial(ctx context.Context, config Config) (*Client, error) {
+	config = config.withDefaults()
+	c := &Client{
+		config:   config,
+		sequence: NewSequenceTracker(),
+		errs:     make(chan error, config.ErrorsBuffer),
+		done:     make(chan struct{}),
+		matches:  make(map[uint32]bool),
+		pending:  make(map[uint32]chan Message),
+	}
+
+	conn, dec, err := c.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.conn = conn
+
+	c.queue = newMessageQueue(config.MessagesBuffer, config.SlowConsumer)
+	go c.run(conn, dec)
+	return c, nil
+}
+
+func dial(ctx context.Context, config Config) (net.Conn, error) {
+	if config.TLSConfig != nil {
+		d := &tls.Dialer{Config: config.TLSConfig}
+		return d.DialContext(ctx, "tcp", config.Addr)
+	}
+
+	d := &net.Dialer{}
+	return d.DialContext(ctx, "tcp", config.Addr)
+}
+
+// connect dials the server, logs in and registers the matches that the
+// client has registered so far
+func (c *Client) connect(ctx context.Context) (net.Conn, *Decoder, error) {
+	if c.config.DialTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.config.DialTimeout)
+		defer cancel()
+	}
+
+	conn, err := dial(ctx, c.config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dec := NewDecoder(conn)
+	if err := c.login(ctx, conn, dec); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	if ids := c.Matches(); len(ids) > 0 {
+		if err := writeStatus(conn, RegisterRequest(c.config.BookmakerID, ids...)); err != nil {
+			conn.Close()
+			return nil, nil, err
+		}
+	}
+	return conn, dec, nil
+}
+
+// login sends the BookmakerStatus login message and reads the server reply,
+// the exchange is aborted as soon as ctx is done
+func (c *Client) login(ctx context.Context, conn net.Conn, dec *Decoder) error {
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Unix(1, 0))
+	})
+	defer func() {
+		if !stop() {
+			conn.SetDeadline(time.Time{})
+		}
+	}()
+
+	login := LoginRequest(c.config.BookmakerID, c.config.Key)
+	if err := writeStatus(conn, login); err != nil {
+		return loginError(ctx, err)
+	}
+
+	msg, err := dec.Decode()
+	if err != nil {
+		return loginError(ctx, err)
+	}
+	reply, ok := msg.(*BookMakerStatus)
+	if !ok {
+		return errNotLogin
+	}
+	if reply.Type != RequestLogin {
+		return newLoginError(reply)
+	}
+	return nil
+}
+
+// loginError prefers the context error when the login was aborted by it
+func loginError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return fmt.Errorf("liveodds: login: %w", err)
+}
+
+func writeStatus(conn net.Conn, v *BookMakerStatus) error {
+	msg, err := xml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = conn.Write(msg)
+	return err
+}
+
+// Send marshals v and writes it to the server
+func (c *Client) Send(v *BookMakerStatus) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	return writeStatus(c.conn, v)
+}
+
+// Register subscribes to the given matches. The matches are remembered and
+// registered again after every reconnection, so an error here only means
+// that the current connection is gone.
+func (c *Client) Register(matchIDs ...uint32) error {
+	c.mu.Lock()
+	for _, id := range matchIDs {
+		c.matches[id] = true
+	}
+	c.mu.Unlock()
+
+	return c.Send(RegisterRequest(c.config.BookmakerID, matchIDs...))
+}
+
+// Unregister cancels the subscription to the given matches
+func (c *Client) Unregister(matchIDs ...uint32) error {
+	c.mu.Lock()
+	for _, id := range matchIDs {
+		delete(c.matches, id)
+	}
+	c.mu.Unlock()
+
+	return c.Send(UnregisterRequest(c.config.BookmakerID, matchIDs...))
+}
+
+// Request sends req with a new reply number and waits for the message that
+// BetRadar sends back with the same replynr. Any other message keeps being
+// delivered by Next meanwhile, so Next must be called concurrently when many
+// messages are expected before the reply. req itself is not modified. An
+// error reply to req is returned as a RequestError.
+func (c *Client) Request(ctx context.Context, req *BookMakerStatus) (*BetRadarLiveOdds, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.config.RequestTimeout)
+	defer cancel()
+
+	r := *req
+	reply := make(chan Message, 1)
+	c.mu.Lock()
+	c.replyNr++
+	r.ReplyNr = c.replyNr
+	c.pending[r.ReplyNr] = reply
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, r.ReplyNr)
+		c.mu.Unlock()
+	}()
+
+	if err := c.Send(&r); err != nil {
+		return nil, err
+	}
+
+	select {
+	case msg := <-reply:
+		if status, ok := msg.(*BookMakerStatus); ok {
+			return nil, status.Err()
+		}
+		return msg.(*BetRadarLiveOdds), nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("liveodds: no reply to %s request %d: %w", r.Type, r.ReplyNr, ctx.Err())
+	case <-c.done:
+		return nil, ErrClosed
+	}
+}
+
+// deliverReply hands msg, the reply with the given replynr, to the Request
+// waiting for it, if any
+func (c *Client) deliverReply(replyNr uint32, msg Message) bool {
+	if replyNr == 0 {
+		return false
+	}
+
+	c.mu.Lock()
+	reply, ok := c.pending[replyNr]
+	delete(c.pending, replyNr)
+	c.mu.Unlock()
+
+	if ok {
+		reply <- msg
+	}
+	return ok
+}
+
+// Matches returns the IDs of the registered matches in ascending order
+func (c *Client) Matches() []uint32 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ids := make([]uint32, 0, len(c.matches))
+	for id := range c.matches {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// LastAlive returns the Epoch of the last alive message received, it is the
+// zero time until the first one arrives
+func (c *Client) LastAlive() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lastAlive
+}
+
+// Messages returns the channel where the messages from the feed are
+// delivered, the same ones that Next returns so only one of them should be
+// used. The channel is closed when the client stops, then Err tells why.
+func (c *Client) Messages() <-chan Message {
+	return c.queue.out
+}
+
+// Errors returns the channel where the client reports the errors that it
+// recovers from, like a lost connection or a message that can not be
+// decoded, and the error that stops it. The errors caused by Close are not
+// reported. It is closed when the client stops.
+func (c *Client) Errors() <-chan error {
+	return c.errs
+}
+
+// Dropped returns the number of messages discarded by the DropOldest policy
+func (c *Client) Dropped() uint64 {
+	return atomic.LoadUint64(&c.queue.dropped)
+}
+
+// report sends err to the Errors channel unless it is full
+func (c *Client) report(err error) {
+	select {
+	case c.errs <- err:
+	default:
+	}
+}
+
+// Next blocks until the next message from the feed arrives or ctx is done.
+// Reconnections are transparent to Next, it only fails once the Client has
+// been closed or BetRadar rejects the login with a LoginError, when
+// connecting again too.
+func (c *Client) Next(ctx context.Context) (*BetRadarLiveOdds, error) {
+	select {
+	case msg, ok := <-c.queue.out:
+		if !ok {
+			if err := c.Err(); err != nil {
+				return nil, err
+			}
+			return nil, ErrClosed
+		}
+		return msg.(*BetRadarLiveOdds), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Err returns the error that stopped the client, if any
+func (c *Client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Close closes the connection to the server and stops reconnecting
+func (c *Client) Close() error {
+	// the error is set before the queue is closed so Next always finds it
+	stopped := c.stop(ErrClosed)
+	// the messages that were not read are not wanted anymore
+	c.quitOnce.Do(func() { close(c.queue.quit) })
+	if !stopped {
+		return nil
+	}
+
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	return c.conn.Close()
+}
+
+// stop marks the client as closed because of err, it returns false when it
+// was already closed
+func (c *Client) stop(err error) bool {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return false
+	}
+	c.closed = true
+	c.err = err
+	c.mu.Unlock()
+
+	close(c.done)
+	return true
+}
+
+func (c *Client) isClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+// run reads from conn until it fails and then reconnects, over and over
+// until the client is closed or BetRadar rejects the login
+func (c *Client) run(conn net.Conn, dec *Decoder) {
+	defer close(c.errs)
+	defer close(c.queue.in)
+	for {
+		err := c.read(conn, dec)
+		conn.Close()
+		if c.isClosed() {
+			// Close made read fail, neither the error is worth reporting
+			// nor there is anything to reconnect
+			return
+		}
+		c.report(err)
+
+		if conn, dec = c.reconnect(); conn == nil {
+			return
+		}
+		c.sequence.Reconnected()
+	}
+}
+
+// read delivers the messages in conn until it fails or the watchdog fires
+// because no alive message arrived in Config.AliveTimeout
+func (c *Client) read(conn net.Conn, dec *Decoder) error {
+	aliveAt := time.Now()
+	for {
+		conn.SetReadDeadline(aliveAt.Add(c.config.AliveTimeout))
+		m, err := dec.Decode()
+		if err != nil {
+			if _, ok := err.(*MessageError); ok {
+				// the message is lost but the stream is still in sync
+				c.report(err)
+				continue
+			}
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				return ErrAliveTimeout
+			}
+			return err
+		}
+
+		msg, ok := m.(*BetRadarLiveOdds)
+		if !ok {
+			status := m.(*BookMakerStatus)
+			if err := status.Err(); err != nil && !c.deliverReply(status.ReplyNr, status) {
+				c.report(err)
+			}
+			continue
+		}
+		if msg.Kind() == StatusAlive {
+			aliveAt = time.Now()
+			c.mu.Lock()
+			c.lastAlive = msg.Epoch()
+			c.mu.Unlock()
+		}
+		if c.deliverReply(msg.ReplyNr, msg) {
+			continue
+		}
+		c.checkSequence(msg)
+
+		// time spent waiting for the caller does not count as silence
+		// from the server
+		blocked := time.Now()
+		select {
+		case c.queue.in <- msg:
+			aliveAt = aliveAt.Add(time.Since(blocked))
+		case <-c.done:
+			return ErrClosed
+		}
+	}
+}
+
+// checkSequence reports the msgnr anomalies of msg and asks for the current
+// odds of the matches with gaps when Config.RequestOddsOnGap is set
+func (c *Client) checkSequence(msg *BetRadarLiveOdds) {
+	for _, ev := range c.sequence.Apply(msg) {
+		if c.config.OnSequenceEvent != nil {
+			c.config.OnSequenceEvent(ev)
+		}
+		if ev.Kind == SequenceGap && c.config.RequestOddsOnGap {
+			c.Send(CurrentOddsRequest(c.config.BookmakerID, ev.MatchID))
+		}
+	}
+}
+
+// reconnect tries to connect again waiting between attempts an exponential
+// backoff, it returns a nil conn when the client is closed meanwhile or the
+// login is rejected, retrying would not help then
+func (c *Client) reconnect() (net.Conn, *Decoder) {
+	select {
+	case <-c.done:
+		return nil, nil
+	default:
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-c.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	backoff := c.config.MinBackoff
+	for {
+		conn, dec, err := c.connect(ctx)
+		if err == nil {
+			c.wmu.Lock()
+			defer c.wmu.Unlock()
+			if c.isClosed() {
+				conn.Close()
+				return nil, nil
+			}
+			c.conn = conn
+			return conn, dec
+		}
+
+		if c.isClosed() {
+			// the attempt was canceled by Close
+			return nil, nil
+		}
+		c.report(err)
+		var loginErr *LoginError
+		if errors.As(err, &loginErr) {
+			c.stop(err)
+			return nil, nil
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-c.done:
+			return nil, nil
+		}
+		if backoff *= 2; backoff > c.config.MaxBackoff {
+			backoff = c.config.MaxBackoff
+		}
+	}
+}
@@ -0,0 +1,729 @@
+package liveodds
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// fakeServer is a local LiveOdds server that runs handler for every
+// connection it accepts, n is the number of the connection starting at 1
+type fakeServer struct {
+	ln      net.Listener
+	handler func(n int, conn *fakeConn)
+}
+
+// fakeConn is the server side of a client connection
+type fakeConn struct {
+	net.Conn
+	dec *Decoder
+}
+
+func newFakeServer(t *testing.T, handler func(int, *fakeConn)) *fakeServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	check(err)
+	return startFakeServer(t, ln, handler)
+}
+
+func startFakeServer(t *testing.T, ln net.Listener, handler func(int, *fakeConn)) *fakeServer {
+	s := &fakeServer{ln: ln, handler: handler}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for n := 1; ; n++ {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(n int) {
+				defer conn.Close()
+				s.handler(n, &fakeConn{Conn: conn, dec: NewDecoder(conn)})
+			}(n)
+		}
+	}()
+	return s
+}
+
+func (s *fakeServer) Addr() string {
+	return s.ln.Addr().String()
+}
+
+// read returns the next BookmakerStatus sent by the client
+func (c *fakeConn) read() (*BookMakerStatus, error) {
+	msg, err := c.dec.Decode()
+	if err != nil {
+		return nil, err
+	}
+	return msg.(*BookMakerStatus), nil
+}
+
+// acceptLogin reads the login message and replies to it
+func (c *fakeConn) acceptLogin() (*BookMakerStatus, error) {
+	login, err := c.read()
+	if err != nil {
+		return nil, err
+	}
+	_, err = c.Write([]byte(`<BookmakerStatus timestamp="0" type="login" bookmakerid="1234"/>`))
+	return login, err
+}
+
+// sendFixtures writes the given fixtures one after another in the conn
+func (c *fakeConn) sendFixtures(fixtures ...string) {
+	for _, fixture := range fixtures {
+		msg, err := ioutil.ReadFile(fixture)
+		check(err)
+		c.Write(msg)
+	}
+}
+
+func testContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func TestClientLoginAndStream(t *testing.T) {
+	logins := make(chan *BookMakerStatus, 1)
+	srv := newFakeServer(t, func(n int, conn *fakeConn) {
+		login, err := conn.acceptLogin()
+		if err != nil {
+			return
+		}
+		logins <- login
+		conn.sendFixtures("fixtures/alive.xml", "fixtures/change.xml")
+		time.Sleep(time.Second)
+	})
+
+	ctx := testContext(t)
+	c, err := Dial(ctx, Config{Addr: srv.Addr(), BookmakerID: 1234, Key: "secret"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer c.Close()
+
+	login := <-logins
+	alive, err := c.Next(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	change, err := c.Next(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var xmlTests = []xmlTest{
+		{login.Type, "login"},
+		{login.BookmakerID, uint16(1234)},
+		{login.Key, "secret"},
+		{alive.Status, "alive"},
+		{change.Status, "change"},
+		{change.Matches[0].MatchID, uint32(867278)},
+		{len(change.Matches[0].Odds), 5},
+		{c.LastAlive(), alive.Epoch()},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestClientLoginAndStream", tt.expected, tt.n)
+		}
+	}
+}
+
+func TestClientLoginRejected(t *testing.T) {
+	srv := newFakeServer(t, func(n int, conn *fakeConn) {
+		conn.read()
+		conn.Write([]byte(`<BookmakerStatus timestamp="0" type="error" bookmakerid="1234"/>`))
+	})
+
+	c, err := Dial(testContext(t), Config{Addr: srv.Addr(), BookmakerID: 1234})
+	if err == nil {
+		c.Close()
+		t.Fatal("expected login error, got nil")
+	}
+	if !errors.Is(err, ErrLoginFailed) {
+		t.Errorf(failed_msg, "TestClientLoginRejected", ErrLoginFailed, err)
+	}
+}
+
+func TestClientLoginErrors(t *testing.T) {
+	var tests = []struct {
+		reply    string
+		expected error
+	}{
+		{"Invalid bookmaker id 1234", ErrLoginFailed},
+		{"IP 127.0.0.1 is not allowed", ErrLoginFailed},
+		{"Something unexpected happened", ErrLoginFailed},
+		{"", ErrLoginFailed},
+	}
+
+	for _, tt := range tests {
+		reply := tt.reply
+		srv := newFakeServer(t, func(n int, conn *fakeConn) {
+			conn.read()
+			fmt.Fprintf(conn, `<BookmakerStatus timestamp="0" type="error" bookmakerid="1234">%s</BookmakerStatus>`, reply)
+		})
+
+		_, err := Dial(testContext(t), Config{Addr: srv.Addr(), BookmakerID: 1234})
+		if !errors.Is(err, tt.expected) {
+			t.Errorf(failed_msg, "TestClientLoginErrors", tt.expected, err)
+		}
+	}
+}
+
+func TestClientRejectedOnReconnect(t *testing.T) {
+	srv := newFakeServer(t, func(n int, conn *fakeConn) {
+		if n == 1 {
+			conn.acceptLogin()
+			return
+		}
+		conn.read()
+		conn.Write([]byte(`<BookmakerStatus timestamp="0" type="error" bookmakerid="1234">Unauthorized IP</BookmakerStatus>`))
+	})
+
+	ctx := testContext(t)
+	c, err := Dial(ctx, Config{Addr: srv.Addr(), MinBackoff: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer c.Close()
+
+	if _, err := c.Next(ctx); !errors.Is(err, ErrLoginFailed) {
+		t.Errorf(failed_msg, "TestClientRejectedOnReconnect", ErrLoginFailed, err)
+	}
+	if err := c.Register(1); err == nil {
+		t.Error("expected an error registering on a rejected client")
+	}
+}
+
+func TestClientErrorReply(t *testing.T) {
+	srv := newFakeServer(t, func(n int, conn *fakeConn) {
+		if _, err := conn.acceptLogin(); err != nil {
+			return
+		}
+		conn.sendFixtures("fixtures/alive.xml")
+		conn.Write([]byte(`<BookmakerStatus timestamp="0" type="error" bookmakerid="1234">Invalid bookmaker</BookmakerStatus>`))
+		time.Sleep(time.Second)
+	})
+
+	ctx := testContext(t)
+	c, err := Dial(ctx, Config{Addr: srv.Addr()})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer c.Close()
+
+	if _, err := c.Next(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// an error reply out of a login does not end the session, whatever it says
+	if reported := <-c.Errors(); !errors.Is(reported, ErrRequestFailed) {
+		t.Errorf(failed_msg, "TestClientErrorReply", ErrRequestFailed, reported)
+	}
+	if c.Err() != nil {
+		t.Errorf(failed_msg, "TestClientErrorReply", nil, c.Err())
+	}
+}
+
+func TestClientRequestErrorReply(t *testing.T) {
+	srv := newFakeServer(t, func(n int, conn *fakeConn) {
+		if _, err := conn.acceptLogin(); err != nil {
+			return
+		}
+		req, err := conn.read()
+		if err != nil {
+			return
+		}
+		conn.Write([]byte(`<BookmakerStatus timestamp="0" type="error" bookmakerid="1234">Match 99 not found</BookmakerStatus>`))
+		fmt.Fprintf(conn, `<BookmakerStatus timestamp="0" type="error" bookmakerid="1234" replynr="%d">Match 99 not found</BookmakerStatus>`, req.ReplyNr)
+		conn.sendFixtures("fixtures/alive.xml")
+		time.Sleep(time.Second)
+	})
+
+	ctx := testContext(t)
+	c, err := Dial(ctx, Config{Addr: srv.Addr(), BookmakerID: 1234})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer c.Close()
+
+	_, reqErr := c.Request(ctx, CurrentOddsRequest(1234, 99))
+	reported := <-c.Errors()
+	alive, err := c.Next(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var requestErr *RequestError
+	var xmlTests = []xmlTest{
+		{errors.As(reqErr, &requestErr), true},
+		{errors.Is(reported, ErrRequestFailed), true},
+		{alive.Status, "alive"},
+		{c.Err(), nil},
+	}
+	if requestErr != nil {
+		xmlTests = append(xmlTests, xmlTest{requestErr.ReplyNr, uint32(1)})
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestClientRequestErrorReply", tt.expected, tt.n)
+		}
+	}
+}
+
+func TestClientLoginTimeout(t *testing.T) {
+	srv := newFakeServer(t, func(n int, conn *fakeConn) {
+		time.Sleep(time.Second)
+	})
+
+	config := Config{Addr: srv.Addr(), DialTimeout: 50 * time.Millisecond}
+	_, err := Dial(context.Background(), config)
+	if err != context.DeadlineExceeded {
+		t.Errorf(failed_msg, "TestClientLoginTimeout", context.DeadlineExceeded, err)
+	}
+}
+
+func TestClientClose(t *testing.T) {
+	srv := newFakeServer(t, func(n int, conn *fakeConn) {
+		if _, err := conn.acceptLogin(); err != nil {
+			return
+		}
+		conn.sendFixtures("fixtures/alive.xml")
+		time.Sleep(time.Second)
+	})
+
+	ctx := testContext(t)
+	c, err := Dial(ctx, Config{Addr: srv.Addr()})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := c.Next(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	c.Close()
+	if _, err := c.Next(ctx); err != ErrClosed {
+		t.Errorf(failed_msg, "TestClientClose", ErrClosed, err)
+	}
+	if err := c.Register(1); err == nil {
+		t.Error("expected an error registering on a closed client")
+	}
+}
+
+func TestClientCloseWhileNext(t *testing.T) {
+	srv := newFakeServer(t, func(n int, conn *fakeConn) {
+		if _, err := conn.acceptLogin(); err != nil {
+			return
+		}
+		time.Sleep(time.Second)
+	})
+
+	ctx := testContext(t)
+	for i := 0; i < 20; i++ {
+		c, err := Dial(ctx, Config{Addr: srv.Addr()})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		errs := make(chan error, 1)
+		go func() {
+			msg, err := c.Next(ctx)
+			if msg != nil {
+				err = fmt.Errorf("unexpected message %v", msg)
+			}
+			errs <- err
+		}()
+		c.Close()
+
+		if err := <-errs; !errors.Is(err, ErrClosed) {
+			t.Fatalf(failed_msg, "TestClientCloseWhileNext", ErrClosed, err)
+		}
+	}
+}
+
+func TestClientCloseDoesNotReconnect(t *testing.T) {
+	conns := make(chan int, 2)
+	srv := newFakeServer(t, func(n int, conn *fakeConn) {
+		conns <- n
+		if _, err := conn.acceptLogin(); err != nil {
+			return
+		}
+		time.Sleep(time.Second)
+	})
+
+	c, err := Dial(testContext(t), Config{Addr: srv.Addr(), MinBackoff: time.Millisecond})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	c.Close()
+
+	// Errors is closed once the client does not run anymore
+	for range c.Errors() {
+	}
+	<-conns
+	select {
+	case n := <-conns:
+		t.Errorf(failed_msg, "TestClientCloseDoesNotReconnect", 1, n)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestClientReconnect(t *testing.T) {
+	registers := make(chan *BookMakerStatus, 2)
+	srv := newFakeServer(t, func(n int, conn *fakeConn) {
+		if _, err := conn.acceptLogin(); err != nil {
+			return
+		}
+		register, err := conn.read()
+		if err != nil {
+			return
+		}
+		registers <- register
+		if n == 1 {
+			// drop the first connection right after the register
+			return
+		}
+		conn.sendFixtures("fixtures/alive.xml")
+		time.Sleep(time.Second)
+	})
+
+	ctx := testContext(t)
+	config := Config{Addr: srv.Addr(), MinBackoff: 10 * time.Millisecond}
+	c, err := Dial(ctx, config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer c.Close()
+
+	if err := c.Register(935457, 867278); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	msg, err := c.Next(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	first, second := <-registers, <-registers
+	var xmlTests = []xmlTest{
+		{msg.Status, "alive"},
+		{first.Type, "register"},
+		{len(first.Match), 2},
+		{second.Type, "register"},
+		{len(second.Match), 2},
+		{second.Match[0].MatchID, uint32(867278)},
+		{second.Match[1].MatchID, uint32(935457)},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestClientReconnect", tt.expected, tt.n)
+		}
+	}
+}
+
+func TestClientAliveTimeout(t *testing.T) {
+	conns := make(chan int, 2)
+	srv := newFakeServer(t, func(n int, conn *fakeConn) {
+		if _, err := conn.acceptLogin(); err != nil {
+			return
+		}
+		conns <- n
+		if n == 1 {
+			// a silent server, the client must give up on it
+			conn.sendFixtures("fixtures/change.xml")
+			conn.read()
+			return
+		}
+		conn.sendFixtures("fixtures/alive.xml")
+		time.Sleep(time.Second)
+	})
+
+	ctx := testContext(t)
+	config := Config{
+		Addr:         srv.Addr(),
+		AliveTimeout: 100 * time.Millisecond,
+		MinBackoff:   10 * time.Millisecond,
+	}
+	c, err := Dial(ctx, config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer c.Close()
+
+	change, err := c.Next(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	alive, err := c.Next(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var xmlTests = []xmlTest{
+		{change.Status, "change"},
+		{alive.Status, "alive"},
+		{<-conns, 1},
+		{<-conns, 2},
+		{c.LastAlive(), alive.Epoch()},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestClientAliveTimeout", tt.expected, tt.n)
+		}
+	}
+}
+
+func TestClientTLS(t *testing.T) {
+	// borrow the self signed certificate that httptest generates
+	ts := httptest.NewTLSServer(nil)
+	defer ts.Close()
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", ts.TLS)
+	check(err)
+	srv := startFakeServer(t, ln, func(n int, conn *fakeConn) {
+		if _, err := conn.acceptLogin(); err != nil {
+			return
+		}
+		conn.sendFixtures("fixtures/alive.xml")
+		time.Sleep(time.Second)
+	})
+
+	ctx := testContext(t)
+	config := Config{
+		Addr:      srv.Addr(),
+		TLSConfig: ts.Client().Transport.(*http.Transport).TLSClientConfig,
+	}
+	c, err := Dial(ctx, config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer c.Close()
+
+	msg, err := c.Next(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if msg.Status != "alive" {
+		t.Errorf(failed_msg, "TestClientTLS", "alive", msg.Status)
+	}
+}
+
+func TestClientRequest(t *testing.T) {
+	requests := make(chan *BookMakerStatus, 2)
+	srv := newFakeServer(t, func(n int, conn *fakeConn) {
+		if _, err := conn.acceptLogin(); err != nil {
+			return
+		}
+		for {
+			req, err := conn.read()
+			if err != nil {
+				return
+			}
+			requests <- req
+
+			// an unsolicited push arrives before the reply
+			conn.sendFixtures("fixtures/alive.xml")
+			card, err := ioutil.ReadFile("fixtures/card.xml")
+			check(err)
+			replyNr := fmt.Sprintf(`replynr="%d"`, req.ReplyNr)
+			conn.Write(bytes.Replace(card, []byte(`replynr="1"`), []byte(replyNr), 1))
+		}
+	})
+
+	ctx := testContext(t)
+	c, err := Dial(ctx, Config{Addr: srv.Addr(), BookmakerID: 1234})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer c.Close()
+
+	req := ScoreAndCardSummaryRequest(1234, 1355389)
+	first, err := c.Request(ctx, req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	second, err := c.Request(ctx, req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	alive, err := c.Next(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var xmlTests = []xmlTest{
+		{(<-requests).ReplyNr, uint32(1)},
+		{(<-requests).ReplyNr, uint32(2)},
+		{req.ReplyNr, uint32(0)},
+		{first.ReplyType, "scoreandcardsummary"},
+		{first.ReplyNr, uint32(1)},
+		{len(first.Matches[0].Card), 2},
+		{second.ReplyNr, uint32(2)},
+		{alive.Status, "alive"},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestClientRequest", tt.expected, tt.n)
+		}
+	}
+}
+
+func TestClientRequestTimeout(t *testing.T) {
+	srv := newFakeServer(t, func(n int, conn *fakeConn) {
+		if _, err := conn.acceptLogin(); err != nil {
+			return
+		}
+		conn.read()
+		time.Sleep(time.Second)
+	})
+
+	ctx := testContext(t)
+	config := Config{Addr: srv.Addr(), RequestTimeout: 50 * time.Millisecond}
+	c, err := Dial(ctx, config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer c.Close()
+
+	_, err = c.Request(ctx, CurrentOddsRequest(1234, 867278))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf(failed_msg, "TestClientRequestTimeout", context.DeadlineExceeded, err)
+	}
+}
+
+func TestClientRequestOddsOnGap(t *testing.T) {
+	requests := make(chan *BookMakerStatus, 1)
+	srv := newFakeServer(t, func(n int, conn *fakeConn) {
+		if _, err := conn.acceptLogin(); err != nil {
+			return
+		}
+		change, err := ioutil.ReadFile("fixtures/change.xml")
+		check(err)
+		conn.Write(change)
+		conn.Write(bytes.Replace(change, []byte(`msgnr="2"`), []byte(`msgnr="5"`), 1))
+
+		req, err := conn.read()
+		if err != nil {
+			return
+		}
+		requests <- req
+		time.Sleep(time.Second)
+	})
+
+	events := make(chan SequenceEvent, 1)
+	ctx := testContext(t)
+	config := Config{
+		Addr:             srv.Addr(),
+		BookmakerID:      1234,
+		RequestOddsOnGap: true,
+		OnSequenceEvent:  func(ev SequenceEvent) { events <- ev },
+	}
+	c, err := Dial(ctx, config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer c.Close()
+
+	req := <-requests
+	var xmlTests = []xmlTest{
+		{<-events, SequenceEvent{SequenceGap, 867278, 3, 5}},
+		{req.Type, RequestCurrentOdds},
+		{len(req.Match), 1},
+		{req.Match[0].MatchID, uint32(867278)},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestClientRequestOddsOnGap", tt.expected, tt.n)
+		}
+	}
+}
+
+func TestClientMessagesAndErrors(t *testing.T) {
+	srv := newFakeServer(t, func(n int, conn *fakeConn) {
+		if _, err := conn.acceptLogin(); err != nil {
+			return
+		}
+		conn.sendFixtures("fixtures/alive.xml")
+		conn.Write([]byte(`<BetradarLiveOdds status="change" timestamp="now"/>`))
+		conn.sendFixtures("fixtures/change.xml")
+		time.Sleep(time.Second)
+	})
+
+	ctx := testContext(t)
+	c, err := Dial(ctx, Config{Addr: srv.Addr(), MessagesBuffer: 1, ErrorsBuffer: 1})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	alive := (<-c.Messages()).(*BetRadarLiveOdds)
+	change := (<-c.Messages()).(*BetRadarLiveOdds)
+	decodeErr := <-c.Errors()
+	c.Close()
+
+	var msgErr *MessageError
+	var xmlTests = []xmlTest{
+		{alive.Status, "alive"},
+		{change.Status, "change"},
+		{errors.As(decodeErr, &msgErr), true},
+	}
+	for range c.Messages() {
+	}
+	var closeErrs []error
+	for err := range c.Errors() {
+		closeErrs = append(closeErrs, err)
+	}
+	xmlTests = append(xmlTests, xmlTest{c.Err(), ErrClosed}, xmlTest{len(closeErrs), 0})
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestClientMessagesAndErrors", tt.expected, tt.n)
+		}
+	}
+}
+
+func TestClientDropOldest(t *testing.T) {
+	srv := newFakeServer(t, func(n int, conn *fakeConn) {
+		if _, err := conn.acceptLogin(); err != nil {
+			return
+		}
+		for i := 0; i < 5; i++ {
+			conn.sendFixtures("fixtures/change.xml")
+		}
+		conn.sendFixtures("fixtures/alive.xml")
+		time.Sleep(time.Second)
+	})
+
+	ctx := testContext(t)
+	c, err := Dial(ctx, Config{Addr: srv.Addr(), MessagesBuffer: 2, SlowConsumer: DropOldest})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer c.Close()
+
+	// wait for the whole stream to be buffered
+	for c.Dropped() < 4 {
+		select {
+		case <-ctx.Done():
+			t.Fatalf(failed_msg, "TestClientDropOldest", 4, c.Dropped())
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	change, err := c.Next(ctx)
+	check(err)
+	alive, err := c.Next(ctx)
+	check(err)
+	if change.Status != "change" || alive.Status != "alive" {
+		t.Errorf(failed_msg, "TestClientDropOldest", "change and alive", change.Status+" and "+alive.Status)
+	}
+}
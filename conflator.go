@@ -0,0 +1,157 @@
+// Copyright 2013 Oscar Campos <oscar.campos@member.fsf.org>
+// See LICENSE file for details.
+
+package liveodds
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// OddsUpdate is the result of merging every update of an odds of a match
+// received since the last time the updates were taken from a Conflator
+type OddsUpdate struct {
+	MatchID   uint32
+	Timestamp time.Time // Epoch of the last message merged
+	Odd       Odd       // the last attributes and the last value of every OddsField
+	Merged    int       // number of updates merged
+}
+
+// Conflator merges the odds updates of the change messages by match and
+// OddsID, so a consumer that can not keep up with the feed, like a pricing
+// engine during a goal, only gets the last state of every odds:
+//
+//	conflator := NewConflator(100 * time.Millisecond)
+//	go func() {
+//		for msg := range client.Messages() {
+//			conflator.Apply(msg.(*BetRadarLiveOdds))
+//		}
+//	}()
+//	for {
+//		updates, err := conflator.Next(ctx)
+//		...
+//	}
+//
+// Next waits for the window after the first pending update, and while the
+// consumer is busy the updates keep being merged. Only the odds of change
+// messages are conflated, any other message has to be handled apart.
+// It is safe to use from several goroutines.
+type Conflator struct {
+	window time.Duration
+
+	mu      sync.Mutex
+	pending map[marketKey]*OddsUpdate
+	order   []marketKey // in order of arrival of the first update
+	first   time.Time   // arrival of the first pending update
+	notify  chan struct{}
+}
+
+// NewConflator creates a Conflator that holds the updates for window before
+// releasing them, with a zero window they are released as soon as the
+// consumer asks for them
+func NewConflator(window time.Duration) *Conflator {
+	return &Conflator{
+		window:  window,
+		pending: make(map[marketKey]*OddsUpdate),
+		notify:  make(chan struct{}),
+	}
+}
+
+// Apply merges the odds of a change message with the pending ones, it
+// reports false when msg is not a change message and so it is ignored
+func (c *Conflator) Apply(msg *BetRadarLiveOdds) bool {
+	if msg.Kind() != StatusChange {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	timestamp := msg.Epoch()
+	for i := range msg.Matches {
+		m := &msg.Matches[i]
+		for _, odd := range m.Odds {
+			key := marketKey{m.MatchID, odd.OddsID}
+			update, ok := c.pending[key]
+			if !ok {
+				if len(c.order) == 0 {
+					c.first = time.Now()
+					close(c.notify)
+					c.notify = make(chan struct{})
+				}
+				odd.OddsField = append([]OddsField(nil), odd.OddsField...)
+				c.pending[key] = &OddsUpdate{MatchID: m.MatchID, Timestamp: timestamp, Odd: odd, Merged: 1}
+				c.order = append(c.order, key)
+				continue
+			}
+			update.Odd = mergeOdd(update.Odd, odd)
+			update.Timestamp = timestamp
+			update.Merged++
+		}
+	}
+	return true
+}
+
+// Len returns the number of pending odds updates
+func (c *Conflator) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.order)
+}
+
+// Flush returns the pending updates, in the order in which their first
+// update arrived, without waiting for the window
+func (c *Conflator) Flush() []OddsUpdate {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.flush()
+}
+
+func (c *Conflator) flush() []OddsUpdate {
+	if len(c.order) == 0 {
+		return nil
+	}
+
+	updates := make([]OddsUpdate, len(c.order))
+	for i, key := range c.order {
+		updates[i] = *c.pending[key]
+		delete(c.pending, key)
+	}
+	c.order = c.order[:0]
+	return updates
+}
+
+// Next blocks until there are pending updates and the window of the first
+// one has passed, then it returns all of them like Flush
+func (c *Conflator) Next(ctx context.Context) ([]OddsUpdate, error) {
+	for {
+		c.mu.Lock()
+		var timer *time.Timer
+		var wait <-chan time.Time
+		if len(c.order) > 0 {
+			left := time.Until(c.first.Add(c.window))
+			if left <= 0 {
+				updates := c.flush()
+				c.mu.Unlock()
+				return updates, nil
+			}
+			timer = time.NewTimer(left)
+			wait = timer.C
+		}
+		notify := c.notify
+		c.mu.Unlock()
+
+		select {
+		case <-wait:
+		case <-notify:
+		case <-ctx.Done():
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+}
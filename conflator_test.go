@@ -0,0 +1,108 @@
+package liveodds
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestConflatorApply(t *testing.T) {
+	c := NewConflator(0)
+
+	first := changeMessage(1, 10, "1.5")
+	first.Matches[0].Odds[0].OddsField = append(first.Matches[0].Odds[0].OddsField, OddsField{Type: "x", Value: "3.1"})
+	second := changeMessage(2, 10, "2.0")
+	third := changeMessage(1, 10, "1.6")
+	third.Matches[0].Odds[0].Active = false
+	third.Timestamp = 1371393502452
+
+	alive := LoadXMLFixture("fixtures/alive.xml")
+	var xmlTests = []xmlTest{
+		{c.Apply(first), true},
+		{c.Apply(second), true},
+		{c.Apply(third), true},
+		{c.Apply(&alive), false},
+		{c.Len(), 2},
+	}
+
+	updates := c.Flush()
+	if len(updates) != 2 {
+		t.Fatalf(failed_msg, "TestConflatorApply", 2, len(updates))
+	}
+	merged := updates[0]
+	xmlTests = append(xmlTests, []xmlTest{
+		{merged.MatchID, uint32(1)},
+		{merged.Merged, 2},
+		{merged.Timestamp, EpochTime(1371393502452)},
+		{merged.Odd.Active, false},
+		{len(merged.Odd.OddsField), 2},
+		{merged.Odd.OddsField[0].Value, "1.6"},
+		{merged.Odd.OddsField[1].Value, "3.1"},
+		{updates[1].MatchID, uint32(2)},
+		{updates[1].Merged, 1},
+		{c.Len(), 0},
+		{len(c.Flush()), 0},
+	}...)
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestConflatorApply", tt.expected, tt.n)
+		}
+	}
+}
+
+func TestConflatorDoesNotShareFields(t *testing.T) {
+	c := NewConflator(0)
+	msg := changeMessage(1, 10, "1.5")
+	c.Apply(msg)
+	msg.Matches[0].Odds[0].OddsField[0].Value = "9.9"
+
+	updates := c.Flush()
+	if value := updates[0].Odd.OddsField[0].Value; value != "1.5" {
+		t.Errorf(failed_msg, "TestConflatorDoesNotShareFields", "1.5", value)
+	}
+}
+
+func TestConflatorNext(t *testing.T) {
+	window := 50 * time.Millisecond
+	c := NewConflator(window)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	go func() {
+		c.Apply(changeMessage(1, 10, "1.5"))
+		c.Apply(changeMessage(1, 10, "1.6"))
+		c.Apply(changeMessage(1, 10, "1.7"))
+	}()
+
+	start := time.Now()
+	updates, err := c.Next(ctx)
+	check(err)
+
+	var xmlTests = []xmlTest{
+		{time.Since(start) >= window, true},
+		{len(updates), 1},
+		{updates[0].Merged, 3},
+		{updates[0].Odd.OddsField[0].Value, "1.7"},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestConflatorNext", tt.expected, tt.n)
+		}
+	}
+}
+
+func TestConflatorNextCanceled(t *testing.T) {
+	c := NewConflator(time.Hour)
+	c.Apply(changeMessage(1, 10, "1.5"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := c.Next(ctx); err != context.DeadlineExceeded {
+		t.Errorf(failed_msg, "TestConflatorNextCanceled", context.DeadlineExceeded, err)
+	}
+	if c.Len() != 1 {
+		t.Errorf(failed_msg, "TestConflatorNextCanceled", 1, c.Len())
+	}
+}
@@ -0,0 +1,254 @@
+// Copyright 2013 Oscar Campos <oscar.campos@member.fsf.org>
+// See LICENSE file for details.
+
+package liveodds
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// maxTagLen is the longest tag name (prefix included) that the Decoder looks
+// at when it tries to find the start of the next message after a malformed one
+const maxTagLen = 64
+
+// Message is a top level document sent through the feed, it is always a
+// *BetRadarLiveOdds or a *BookMakerStatus
+type Message interface {
+	isMessage()
+}
+
+func (*BetRadarLiveOdds) isMessage() {}
+func (*BookMakerStatus) isMessage()  {}
+
+// MessageError is returned by Decode when a message is well formed XML but
+// it can not be unmarshaled into its type. The message is discarded and the
+// Decoder can still be used to read the messages that come after it.
+type MessageError struct {
+	Name string // local name of the root element
+	Err  error
+}
+
+func (e *MessageError) Error() string {
+	return fmt.Sprintf("liveodds: can not decode %s message: %v", e.Name, e.Err)
+}
+
+func (e *MessageError) Unwrap() error {
+	return e.Err
+}
+
+// Decoder reads the stream of XML documents that BetRadar sends one after
+// another through the same connection and returns them one at a time:
+//
+//	dec := NewDecoder(conn)
+//	for {
+//	    msg, err := dec.Decode()
+//	    if err != nil {
+//	        return err
+//	    }
+//	    switch m := msg.(type) {
+//	    case *BetRadarLiveOdds:
+//	        ...
+//	    case *BookMakerStatus:
+//	        ...
+//	    }
+//	}
+//
+// Anything between two messages that is not a BetradarLiveOdds or a
+// BookmakerStatus element is ignored. When the stream is not well formed
+// the Decoder discards its content until the start of the next message.
+type Decoder struct {
+	r   *syncReader
+	dec *xml.Decoder
+}
+
+// NewDecoder creates a new Decoder reading from r
+func NewDecoder(r io.Reader) *Decoder {
+	d := &Decoder{r: &syncReader{r: bufio.NewReader(r)}}
+	d.dec = xml.NewDecoder(d.r)
+	return d
+}
+
+// Decode returns the next message in the stream. It returns io.EOF when the
+// stream ends between two messages and io.ErrUnexpectedEOF when it ends in
+// the middle of one.
+func (d *Decoder) Decode() (Message, error) {
+	for {
+		start, tokens, err := d.next()
+		if err != nil {
+			if _, ok := err.(*xml.SyntaxError); !ok {
+				return nil, err
+			}
+			if err := d.resync(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		var msg Message
+		switch start.Name.Local {
+		case "BetradarLiveOdds":
+			msg = new(BetRadarLiveOdds)
+		case "BookmakerStatus":
+			msg = new(BookMakerStatus)
+		default:
+			continue
+		}
+
+		if err := unmarshalTokens(msg, tokens); err != nil {
+			return nil, &MessageError{Name: start.Name.Local, Err: err}
+		}
+		return msg, nil
+	}
+}
+
+// next reads the next top level element from the stream and returns its
+// start element and all of its tokens, so a message is always consumed as
+// a whole even if it can not be unmarshaled later
+func (d *Decoder) next() (start xml.StartElement, tokens []xml.Token, err error) {
+	depth := 0
+	for {
+		tok, err := d.dec.Token()
+		if err != nil {
+			return start, nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				start = t.Copy()
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+
+		if depth == 0 && tokens == nil {
+			// text, comments or processing instructions between messages
+			continue
+		}
+		tokens = append(tokens, xml.CopyToken(tok))
+		if depth == 0 {
+			return start, tokens, nil
+		}
+	}
+}
+
+// resync discards the stream content until the start tag of the next
+// message and replaces the underlying xml.Decoder as it can not be used
+// anymore after a syntax error
+func (d *Decoder) resync() error {
+	if d.r.last == '<' && d.r.rootAt(0) {
+		// the xml.Decoder failed right after reading the '<' of the next
+		// message so it has to be read again
+		d.r.unread = true
+		d.dec = xml.NewDecoder(d.r)
+		return nil
+	}
+
+	for {
+		b, err := d.r.r.Peek(1)
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return err
+		}
+		if b[0] == '<' && d.r.rootAt(1) {
+			d.dec = xml.NewDecoder(d.r)
+			return nil
+		}
+		d.r.r.Discard(1)
+	}
+}
+
+// unmarshalTokens unmarshals the tokens of a whole element into v
+func unmarshalTokens(v interface{}, tokens []xml.Token) error {
+	dec := xml.NewTokenDecoder(&tokenReader{tokens: tokens})
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	start := tok.(xml.StartElement)
+	return dec.DecodeElement(v, &start)
+}
+
+// tokenReader is a xml.TokenReader over an already read list of tokens
+type tokenReader struct {
+	tokens []xml.Token
+}
+
+func (r *tokenReader) Token() (xml.Token, error) {
+	if len(r.tokens) == 0 {
+		return nil, io.EOF
+	}
+	tok := r.tokens[0]
+	r.tokens = r.tokens[1:]
+	return tok, nil
+}
+
+// syncReader is the io.ByteReader that the xml.Decoder reads from, it keeps
+// the last byte read so the Decoder can find out where a message starts after
+// a syntax error
+type syncReader struct {
+	r      *bufio.Reader
+	last   byte
+	unread bool // last has to be returned again by the next ReadByte
+}
+
+func (s *syncReader) ReadByte() (byte, error) {
+	if s.unread {
+		s.unread = false
+		return s.last, nil
+	}
+
+	b, err := s.r.ReadByte()
+	if err == nil {
+		s.last = b
+	}
+	return b, err
+}
+
+func (s *syncReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	b, err := s.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	p[0] = b
+	return 1, nil
+}
+
+// rootAt reports whether the buffered stream has the tag name of a message
+// root element at offset. It peeks one byte at a time so it never blocks
+// waiting for data that goes past the end of the tag name.
+func (s *syncReader) rootAt(offset int) bool {
+	for n := offset + 1; n <= offset+maxTagLen; n++ {
+		buf, err := s.r.Peek(n)
+		if err != nil {
+			return false
+		}
+
+		switch buf[n-1] {
+		case ' ', '\t', '\r', '\n', '/', '>':
+			name := buf[offset : n-1]
+			for i, c := range name {
+				if c == ':' {
+					name = name[i+1:]
+					break
+				}
+			}
+			return isRootName(string(name))
+		}
+	}
+	return false
+}
+
+func isRootName(name string) bool {
+	return name == "BetradarLiveOdds" || name == "BookmakerStatus"
+}
@@ -0,0 +1,186 @@
+package liveodds
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+var streamFixtures = []string{
+	"fixtures/alive.xml",
+	"fixtures/betstart.xml",
+	"fixtures/change.xml",
+	"fixtures/clearbet.xml",
+	"fixtures/registerreply.xml",
+	"fixtures/translation.xml",
+}
+
+// loadStream concatenates the given fixtures the same way BetRadar sends
+// messages one after another through the socket
+func loadStream(fixtures ...string) []byte {
+	var stream bytes.Buffer
+	for _, fixture := range fixtures {
+		msg, err := ioutil.ReadFile(fixture)
+		check(err)
+		stream.Write(msg)
+	}
+	return stream.Bytes()
+}
+
+// decodeStatuses decodes every message in r and returns their status
+func decodeStatuses(t *testing.T, r io.Reader) (statuses []string) {
+	dec := NewDecoder(r)
+	for {
+		msg, err := dec.Decode()
+		if err == io.EOF {
+			return statuses
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		switch m := msg.(type) {
+		case *BetRadarLiveOdds:
+			statuses = append(statuses, m.Status)
+		case *BookMakerStatus:
+			statuses = append(statuses, m.Type)
+		}
+	}
+}
+
+func checkStatuses(t *testing.T, name string, current, expected []string) {
+	if len(current) != len(expected) {
+		t.Fatalf(failed_msg, name, expected, current)
+	}
+	for i := range expected {
+		if current[i] != expected[i] {
+			t.Errorf(failed_msg, name, expected[i], current[i])
+		}
+	}
+}
+
+func TestDecoderConcatenatedFixtures(t *testing.T) {
+	expected := []string{"alive", "betstart", "change", "clearbet", "meta", "translation"}
+	stream := loadStream(streamFixtures...)
+
+	checkStatuses(t, "TestDecoderConcatenatedFixtures",
+		decodeStatuses(t, bytes.NewReader(stream)), expected)
+	checkStatuses(t, "TestDecoderConcatenatedFixtures",
+		decodeStatuses(t, iotest.OneByteReader(bytes.NewReader(stream))), expected)
+	checkStatuses(t, "TestDecoderConcatenatedFixtures",
+		decodeStatuses(t, iotest.HalfReader(bytes.NewReader(stream))), expected)
+}
+
+func TestDecoderFields(t *testing.T) {
+	dec := NewDecoder(bytes.NewReader(loadStream("fixtures/betstop.xml", "fixtures/change.xml")))
+	msg, err := dec.Decode()
+	check(err)
+	betstop := msg.(*BetRadarLiveOdds)
+	msg, err = dec.Decode()
+	check(err)
+	change := msg.(*BetRadarLiveOdds)
+
+	var xmlTests = []xmlTest{
+		{betstop.Status, "betstop"},
+		{betstop.Timestamp, int64(1383789901283)},
+		{betstop.Matches[0].MatchID, uint32(935449)},
+		{betstop.Matches[0].SetScores, "0:1 - 0:0"},
+		{change.Matches[0].Odds[0].OddsField[2].Value, "4.05"},
+		{change.Matches[0].Odds[4].Type, "ft2w"},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestDecoderFields", tt.expected, tt.n)
+		}
+	}
+}
+
+func TestDecoderSplitMessage(t *testing.T) {
+	stream := loadStream("fixtures/alive.xml", "fixtures/change.xml")
+	alive := len(loadStream("fixtures/alive.xml"))
+	cut := alive + 100
+
+	r, w := io.Pipe()
+	defer w.Close()
+	dec := NewDecoder(r)
+	go w.Write(stream[:cut])
+
+	// the first message must be returned without waiting for the second
+	msg, err := dec.Decode()
+	check(err)
+	if status := msg.(*BetRadarLiveOdds).Status; status != "alive" {
+		t.Errorf(failed_msg, "TestDecoderSplitMessage", "alive", status)
+	}
+
+	go w.Write(stream[cut:])
+	msg, err = dec.Decode()
+	check(err)
+	if status := msg.(*BetRadarLiveOdds).Status; status != "change" {
+		t.Errorf(failed_msg, "TestDecoderSplitMessage", "change", status)
+	}
+}
+
+func TestDecoderJunk(t *testing.T) {
+	alive := string(loadStream("fixtures/alive.xml"))
+	betstart := string(loadStream("fixtures/betstart.xml"))
+	change := string(loadStream("fixtures/change.xml"))
+
+	stream := strings.Join([]string{
+		"\x00garbage<<>>",
+		alive,
+		"</Unexpected>",
+		betstart,
+		"<Unknown id=\"1\"><Child/></Unknown>",
+		// truncated message followed by a complete one
+		change[:200],
+		change,
+		`<BookmakerStatus timestamp="0" type="login" bookmakerid="1"/>`,
+		"<<<",
+		alive,
+	}, "\n")
+
+	expected := []string{"alive", "betstart", "change", "login", "alive"}
+	checkStatuses(t, "TestDecoderJunk",
+		decodeStatuses(t, strings.NewReader(stream)), expected)
+	checkStatuses(t, "TestDecoderJunk",
+		decodeStatuses(t, iotest.OneByteReader(strings.NewReader(stream))), expected)
+}
+
+func TestDecoderMessageError(t *testing.T) {
+	stream := `<BetradarLiveOdds status="alive" timestamp="not a number"/>` +
+		string(loadStream("fixtures/alive.xml"))
+	dec := NewDecoder(strings.NewReader(stream))
+
+	_, err := dec.Decode()
+	var msgErr *MessageError
+	if !errors.As(err, &msgErr) || msgErr.Name != "BetradarLiveOdds" {
+		t.Errorf(failed_msg, "TestDecoderMessageError", "*MessageError", err)
+	}
+
+	msg, err := dec.Decode()
+	check(err)
+	if status := msg.(*BetRadarLiveOdds).Status; status != "alive" {
+		t.Errorf(failed_msg, "TestDecoderMessageError", "alive", status)
+	}
+}
+
+func TestDecoderEOF(t *testing.T) {
+	alive := string(loadStream("fixtures/alive.xml"))
+	var xmlTests = []xmlTest{
+		{"", io.EOF},
+		{"  \n", io.EOF},
+		{alive[:50], io.ErrUnexpectedEOF},
+	}
+
+	for _, tt := range xmlTests {
+		_, err := NewDecoder(strings.NewReader(tt.n.(string))).Decode()
+		if err != tt.expected {
+			t.Errorf(failed_msg, "TestDecoderEOF", tt.expected, err)
+		}
+	}
+}
@@ -0,0 +1,336 @@
+// Copyright 2013 Oscar Campos <oscar.campos@member.fsf.org>
+// See LICENSE file for details.
+
+package liveodds
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Default values used for the zero arguments of NewDispatcher
+const (
+	DefaultDispatcherWorkers = 4
+	DefaultDispatcherQueue   = 64
+)
+
+// ErrDispatcherClosed is returned by Dispatch once Close has been called
+var ErrDispatcherClosed = errors.New("liveodds: dispatcher closed")
+
+// MatchEvent is the part of a match of a message that is given to the
+// Dispatcher handlers, the handlers must not modify it
+type MatchEvent struct {
+	Timestamp time.Time // Epoch of the message
+	Match     Match
+	Info      MatchInfo // last MatchInfo received for the match, if any
+}
+
+// OddsChangeEvent is a match of a change message
+type OddsChangeEvent struct {
+	MatchEvent
+	Odds []Odd
+}
+
+// ScoreEvent is a match of a score message
+type ScoreEvent struct {
+	MatchEvent
+	Scores []Score
+}
+
+// CardEvent is a match with cards of any message
+type CardEvent struct {
+	MatchEvent
+	Cards []Card
+}
+
+// BetStopEvent is a match of a betstop message
+type BetStopEvent struct {
+	MatchEvent
+}
+
+// ClearBetEvent is a match of a clearbet message
+type ClearBetEvent struct {
+	MatchEvent
+	Odds []Odd
+}
+
+// AliveEvent is a match of an alive message
+type AliveEvent struct {
+	MatchEvent
+}
+
+// Filter selects the matches that a handler receives. Every field that is
+// not empty has to contain the match, Sports and Tournaments are looked up
+// in the MatchInfo of the last meta message of the match so matches that
+// did not get one yet are not selected by them.
+type Filter struct {
+	Sports      []uint32
+	Tournaments []uint32
+	Matches     []uint32
+}
+
+func (f *Filter) match(e *MatchEvent) bool {
+	return containsID(f.Sports, e.Info.Sport.Id) &&
+		containsID(f.Tournaments, e.Info.Tournament.Id) &&
+		containsID(f.Matches, e.Match.MatchID)
+}
+
+// containsID reports whether id is in ids, any id is in empty ids
+func containsID(ids []uint32, id uint32) bool {
+	if len(ids) == 0 {
+		return true
+	}
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+type eventKind uint8
+
+const (
+	eventNone eventKind = iota
+	eventOddsChange
+	eventScore
+	eventCard
+	eventBetStop
+	eventClearBet
+	eventAlive
+)
+
+type handler struct {
+	kind    eventKind
+	filters []Filter
+	call    func(MatchEvent)
+}
+
+func (h *handler) match(e *MatchEvent) bool {
+	if len(h.filters) == 0 {
+		return true
+	}
+	for i := range h.filters {
+		if h.filters[i].match(e) {
+			return true
+		}
+	}
+	return false
+}
+
+// Dispatcher splits the messages of the feed in one event per match and
+// calls the handlers registered for them:
+//
+//	d := NewDispatcher(0, 0)
+//	d.OnOddsChange(func(e OddsChangeEvent) { ... }, Filter{Sports: []uint32{1}})
+//	d.Dispatch(msg) // for every message of the feed
+//
+// The handlers run in a bounded pool of workers, the events of a match
+// always go to the same worker so they are handled in order. A handler is
+// only selected by the filters given when it was registered, when there are
+// many it is enough that one of them selects the match.
+//
+// Handlers can call Dispatch, but it blocks while the queue of the worker
+// of a match is full, even when that worker is the one running the handler,
+// so a handler that dispatches events of its own match can deadlock. Close
+// waits for the handlers and must not be called from one of them.
+type Dispatcher struct {
+	mu       sync.RWMutex
+	handlers []handler
+	info     map[uint32]MatchInfo
+
+	qmu     sync.Mutex // guards closed and the adds to sending
+	closed  bool
+	done    chan struct{} // closed by Close, stops the blocked sends
+	sending sync.WaitGroup
+	queues  []chan func()
+	wg      sync.WaitGroup
+}
+
+// NewDispatcher starts a Dispatcher with the given number of workers, each
+// one with a queue of the given size. Zero values are replaced by
+// DefaultDispatcherWorkers and DefaultDispatcherQueue.
+func NewDispatcher(workers, queue int) *Dispatcher {
+	if workers <= 0 {
+		workers = DefaultDispatcherWorkers
+	}
+	if queue <= 0 {
+		queue = DefaultDispatcherQueue
+	}
+
+	d := &Dispatcher{
+		info:   make(map[uint32]MatchInfo),
+		done:   make(chan struct{}),
+		queues: make([]chan func(), workers),
+	}
+	d.wg.Add(workers)
+	for i := range d.queues {
+		d.queues[i] = make(chan func(), queue)
+		go d.work(d.queues[i])
+	}
+	return d
+}
+
+func (d *Dispatcher) work(queue chan func()) {
+	defer d.wg.Done()
+	for job := range queue {
+		job()
+	}
+}
+
+func (d *Dispatcher) on(kind eventKind, filters []Filter, call func(MatchEvent)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.handlers = append(d.handlers, handler{kind, filters, call})
+}
+
+// OnOddsChange registers a handler for the matches of change messages
+func (d *Dispatcher) OnOddsChange(fn func(OddsChangeEvent), filters ...Filter) {
+	d.on(eventOddsChange, filters, func(e MatchEvent) {
+		fn(OddsChangeEvent{e, e.Match.Odds})
+	})
+}
+
+// OnScore registers a handler for the matches of score messages
+func (d *Dispatcher) OnScore(fn func(ScoreEvent), filters ...Filter) {
+	d.on(eventScore, filters, func(e MatchEvent) {
+		fn(ScoreEvent{e, e.Match.Scores})
+	})
+}
+
+// OnCard registers a handler for the matches with cards of any message
+func (d *Dispatcher) OnCard(fn func(CardEvent), filters ...Filter) {
+	d.on(eventCard, filters, func(e MatchEvent) {
+		fn(CardEvent{e, e.Match.Card})
+	})
+}
+
+// OnBetStop registers a handler for the matches of betstop messages
+func (d *Dispatcher) OnBetStop(fn func(BetStopEvent), filters ...Filter) {
+	d.on(eventBetStop, filters, func(e MatchEvent) {
+		fn(BetStopEvent{e})
+	})
+}
+
+// OnClearBet registers a handler for the matches of clearbet messages
+func (d *Dispatcher) OnClearBet(fn func(ClearBetEvent), filters ...Filter) {
+	d.on(eventClearBet, filters, func(e MatchEvent) {
+		fn(ClearBetEvent{e, e.Match.Odds})
+	})
+}
+
+// OnAlive registers a handler for the matches of alive messages
+func (d *Dispatcher) OnAlive(fn func(AliveEvent), filters ...Filter) {
+	d.on(eventAlive, filters, func(e MatchEvent) {
+		fn(AliveEvent{e})
+	})
+}
+
+// Dispatch queues the events of msg for the handlers that select them, it
+// blocks while the queue of the worker of a match is full. When Close is
+// called meanwhile it returns ErrDispatcherClosed and the events that were
+// not queued yet are dropped.
+func (d *Dispatcher) Dispatch(msg *BetRadarLiveOdds) error {
+	kind := eventNone // only the cards and the MatchInfo are used
+	switch msg.Kind() {
+	case StatusChange:
+		kind = eventOddsChange
+	case StatusScore:
+		kind = eventScore
+	case StatusBetStop:
+		kind = eventBetStop
+	case StatusClearBet:
+		kind = eventClearBet
+	case StatusAlive:
+		kind = eventAlive
+	}
+
+	type job struct {
+		matchID uint32
+		run     func()
+	}
+	var jobs []job
+
+	d.mu.Lock()
+	timestamp := msg.Epoch()
+	for i := range msg.Matches {
+		m := &msg.Matches[i]
+		if !m.MatchInfo.empty() {
+			d.info[m.MatchID] = m.MatchInfo
+		}
+
+		e := MatchEvent{Timestamp: timestamp, Match: *m, Info: d.info[m.MatchID]}
+		for _, h := range d.handlers {
+			if h.kind != kind && (h.kind != eventCard || len(m.Card) == 0) {
+				continue
+			}
+			if h.match(&e) {
+				call := h.call
+				jobs = append(jobs, job{m.MatchID, func() { call(e) }})
+			}
+		}
+	}
+	d.mu.Unlock()
+
+	// no lock is held during the sends, Close waits for them and stops
+	// the blocked ones through done
+	d.qmu.Lock()
+	if d.closed {
+		d.qmu.Unlock()
+		return ErrDispatcherClosed
+	}
+	d.sending.Add(1)
+	d.qmu.Unlock()
+	defer d.sending.Done()
+
+	for _, j := range jobs {
+		select {
+		case d.queues[int(j.matchID%uint32(len(d.queues)))] <- j.run:
+		case <-d.done:
+			return ErrDispatcherClosed
+		}
+	}
+	return nil
+}
+
+// Run dispatches the messages of c until ctx is done or c fails
+func (d *Dispatcher) Run(ctx context.Context, c *Client) error {
+	for {
+		msg, err := c.Next(ctx)
+		if err != nil {
+			return err
+		}
+		if err := d.Dispatch(msg); err != nil {
+			return err
+		}
+	}
+}
+
+// Forget drops the MatchInfo kept for the given match
+func (d *Dispatcher) Forget(matchID uint32) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.info, matchID)
+}
+
+// Close waits for the queued events to be handled and stops the workers,
+// the Dispatch calls blocked on a full queue return ErrDispatcherClosed
+func (d *Dispatcher) Close() {
+	d.qmu.Lock()
+	if d.closed {
+		d.qmu.Unlock()
+		return
+	}
+	d.closed = true
+	close(d.done)
+	d.qmu.Unlock()
+
+	// the queues are closed once nobody can send on them
+	d.sending.Wait()
+	for _, queue := range d.queues {
+		close(queue)
+	}
+	d.wg.Wait()
+}
@@ -0,0 +1,213 @@
+package liveodds
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// eventLog collects the events handled by a Dispatcher
+type eventLog struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (l *eventLog) add(kind string, e MatchEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, kind+" "+fmt.Sprint(e.Match.MatchID))
+}
+
+func (l *eventLog) sorted() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	events := append([]string(nil), l.events...)
+	sort.Strings(events)
+	return events
+}
+
+func dispatchFixtures(d *Dispatcher, fixtures ...string) {
+	for _, fixture := range fixtures {
+		msg := LoadXMLFixture(fixture)
+		check(d.Dispatch(&msg))
+	}
+}
+
+func TestDispatcherEvents(t *testing.T) {
+	d := NewDispatcher(2, 1)
+	log := &eventLog{}
+	var change OddsChangeEvent
+	var score ScoreEvent
+	var cards CardEvent
+
+	d.OnOddsChange(func(e OddsChangeEvent) { change = e; log.add("change", e.MatchEvent) })
+	d.OnScore(func(e ScoreEvent) { log.add("score", e.MatchEvent) })
+	d.OnScore(func(e ScoreEvent) { score = e }, Filter{Matches: []uint32{935449}})
+	d.OnCard(func(e CardEvent) { cards = e; log.add("card", e.MatchEvent) })
+	d.OnBetStop(func(e BetStopEvent) { log.add("betstop", e.MatchEvent) })
+	d.OnClearBet(func(e ClearBetEvent) { log.add("clearbet", e.MatchEvent) })
+	d.OnAlive(func(e AliveEvent) { log.add("alive", e.MatchEvent) })
+
+	dispatchFixtures(d, "fixtures/alive.xml", "fixtures/change.xml", "fixtures/score.xml",
+		"fixtures/card.xml", "fixtures/betstop.xml", "fixtures/clearbet.xml", "fixtures/betstart.xml")
+	d.Close()
+
+	expected := []string{
+		"alive 935457",
+		"betstop 935449",
+		"card 1355389",
+		"change 867278",
+		"clearbet 793862",
+		"score 1355389",
+		"score 935449",
+	}
+	events := log.sorted()
+	if len(events) != len(expected) {
+		t.Fatalf(failed_msg, "TestDispatcherEvents", expected, events)
+	}
+	for i := range expected {
+		if events[i] != expected[i] {
+			t.Errorf(failed_msg, "TestDispatcherEvents", expected[i], events[i])
+		}
+	}
+
+	var xmlTests = []xmlTest{
+		{len(change.Odds), 5},
+		{change.Timestamp, time.Date(2013, time.October, 31, 22, 45, 29, 944*int(time.Millisecond), time.UTC)},
+		{len(cards.Cards), 2},
+		{cards.Cards[0].Player, "Ramires"},
+		{score.Match.MatchID, uint32(935449)},
+		{len(score.Scores), 1},
+	}
+	alive := LoadXMLFixture("fixtures/alive.xml")
+	xmlTests = append(xmlTests, xmlTest{d.Dispatch(&alive), ErrDispatcherClosed})
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestDispatcherEvents", tt.expected, tt.n)
+		}
+	}
+}
+
+func TestDispatcherFilters(t *testing.T) {
+	d := NewDispatcher(0, 0)
+	log := &eventLog{}
+
+	soccer := Filter{Sports: []uint32{1}}
+	premier := Filter{Sports: []uint32{1}, Tournaments: []uint32{1}}
+	d.OnBetStop(func(e BetStopEvent) { log.add("soccer", e.MatchEvent) }, soccer)
+	d.OnBetStop(func(e BetStopEvent) { log.add("premier", e.MatchEvent) }, premier)
+	d.OnBetStop(func(e BetStopEvent) { log.add("tennis", e.MatchEvent) }, Filter{Sports: []uint32{5}})
+	d.OnOddsChange(func(e OddsChangeEvent) { log.add("match", e.MatchEvent) }, Filter{Matches: []uint32{1}}, Filter{Matches: []uint32{867278}})
+	d.OnOddsChange(func(e OddsChangeEvent) { log.add("soccer", e.MatchEvent) }, soccer)
+
+	// the sport of 935449 is only known after the meta message
+	dispatchFixtures(d, "fixtures/betstop.xml", "fixtures/matchinfo.xml", "fixtures/betstop.xml", "fixtures/change.xml")
+	d.Forget(935449)
+	dispatchFixtures(d, "fixtures/betstop.xml")
+	d.Close()
+
+	expected := []string{"match 867278", "premier 935449", "soccer 935449"}
+	events := log.sorted()
+	if len(events) != len(expected) {
+		t.Fatalf(failed_msg, "TestDispatcherFilters", expected, events)
+	}
+	for i := range expected {
+		if events[i] != expected[i] {
+			t.Errorf(failed_msg, "TestDispatcherFilters", expected[i], events[i])
+		}
+	}
+}
+
+func TestDispatcherOrder(t *testing.T) {
+	d := NewDispatcher(3, 1)
+	var mu sync.Mutex
+	var msgnrs []uint32
+	d.OnOddsChange(func(e OddsChangeEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		msgnrs = append(msgnrs, e.Match.MsgNR)
+	})
+
+	for i := uint32(1); i <= 100; i++ {
+		msg := &BetRadarLiveOdds{Status: "change", Matches: []Match{{MatchID: 7, MsgNR: i}}}
+		check(d.Dispatch(msg))
+	}
+	d.Close()
+
+	if len(msgnrs) != 100 {
+		t.Fatalf(failed_msg, "TestDispatcherOrder", 100, len(msgnrs))
+	}
+	for i, msgnr := range msgnrs {
+		if msgnr != uint32(i+1) {
+			t.Fatalf(failed_msg, "TestDispatcherOrder", i+1, msgnr)
+		}
+	}
+}
+
+func TestDispatcherCloseUnblocksDispatch(t *testing.T) {
+	d := NewDispatcher(1, 1)
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	d.OnOddsChange(func(e OddsChangeEvent) {
+		started <- struct{}{}
+		<-release
+	})
+
+	change := func(msgnr uint32) *BetRadarLiveOdds {
+		return &BetRadarLiveOdds{Status: "change", Matches: []Match{{MatchID: 7, MsgNR: msgnr}}}
+	}
+	check(d.Dispatch(change(1)))
+	<-started
+	check(d.Dispatch(change(2))) // fills the queue
+
+	blocked := make(chan error, 1)
+	go func() { blocked <- d.Dispatch(change(3)) }()
+	closed := make(chan struct{})
+	go func() {
+		d.Close()
+		close(closed)
+	}()
+
+	// the blocked Dispatch returns while the handler is still running
+	select {
+	case err := <-blocked:
+		if err != ErrDispatcherClosed {
+			t.Errorf(failed_msg, "TestDispatcherCloseUnblocksDispatch", ErrDispatcherClosed, err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Dispatch still blocked after Close")
+	}
+
+	close(release)
+	<-started // the queued event is still handled
+	<-closed
+	if err := d.Dispatch(change(4)); err != ErrDispatcherClosed {
+		t.Errorf(failed_msg, "TestDispatcherCloseUnblocksDispatch", ErrDispatcherClosed, err)
+	}
+}
+
+func TestDispatcherReentrant(t *testing.T) {
+	d := NewDispatcher(2, 1)
+	log := &eventLog{}
+	handled := make(chan struct{})
+	d.OnScore(func(e ScoreEvent) {
+		log.add("score", e.MatchEvent)
+		close(handled)
+	})
+	d.OnOddsChange(func(e OddsChangeEvent) {
+		// a handler can dispatch events of other matches
+		err := d.Dispatch(&BetRadarLiveOdds{Status: "score", Matches: []Match{{MatchID: e.Match.MatchID + 1}}})
+		check(err)
+	})
+
+	check(d.Dispatch(&BetRadarLiveOdds{Status: "change", Matches: []Match{{MatchID: 2}}}))
+	<-handled
+	d.Close()
+
+	if events := log.sorted(); len(events) != 1 || events[0] != "score 3" {
+		t.Errorf(failed_msg, "TestDispatcherReentrant", []string{"score 3"}, events)
+	}
+}
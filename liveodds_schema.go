@@ -13,9 +13,14 @@ package liveodds
 
 import (
 	"encoding/xml"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// Namespace is the XML namespace of the BetRadarLiveOdds messages
+const Namespace = "http://www.betradar.com/BetradarLiveOdds"
+
 // BetRadarLiveOdds is a Struct ready to XML Unmarshal a BetRadarLiveOdds XML
 // message from BetRadar in play live XML Feeds. It can be used just as:
 //
@@ -35,51 +40,272 @@ type BetRadarLiveOdds struct {
 	StartTime int64    `xml:"starttime,attr,omitempty"`
 	EndTime   int64    `xml:"endtime,attr,omitempty"`
 	ReplyType string   `xml:"replytype,attr,omitempty"`
-	XMLNS     string   `xml:"xmlns,attr"`
+	ReplyNr   uint32   `xml:"replynr,attr,omitempty"`
+	XMLNS     string   `xml:"xmlns,attr"` // namespace of the decoded message
 	Matches   []Match  `xml:"Match"`
 	OddsType  []OddsType
+
+	ExtraAttrs    []xml.Attr   `xml:",any,attr"`
+	ExtraElements []RawElement `xml:",any"`
+}
+
+// UnmarshalXML decodes the message no matter if BetRadar declared the
+// namespace as the default one or with a prefix like ns1:, XMLNS is set to
+// the namespace of the root element in both cases
+func (t *BetRadarLiveOdds) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type betRadarLiveOdds BetRadarLiveOdds // same fields without the UnmarshalXML method
+	if err := d.DecodeElement((*betRadarLiveOdds)(t), &start); err != nil {
+		return err
+	}
+
+	if start.Name.Space != "" {
+		t.XMLNS = start.Name.Space
+	}
+	t.ExtraAttrs = withoutNamespaces(t.ExtraAttrs)
+	return nil
+}
+
+// MarshalXML always writes the message in the canonical Namespace without
+// prefixes, whatever namespace was in the message that was decoded
+func (t BetRadarLiveOdds) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type betRadarLiveOdds BetRadarLiveOdds // same fields without the MarshalXML method
+	v := betRadarLiveOdds(t)
+	v.XMLNS = Namespace
+	v.ExtraAttrs = withoutNamespaces(t.ExtraAttrs)
+	start.Name = xml.Name{Local: "BetradarLiveOdds"}
+	return e.EncodeElement(v, start)
+}
+
+// Marshal returns the XML encoding of the message in the canonical Namespace
+func (t *BetRadarLiveOdds) Marshal() ([]byte, error) {
+	return xml.Marshal(t)
+}
+
+// withoutNamespaces drops the xmlns:prefix declarations from attrs, the
+// prefixes are not used by the encoder
+func withoutNamespaces(attrs []xml.Attr) []xml.Attr {
+	var kept []xml.Attr
+	for _, attr := range attrs {
+		if attr.Name.Space != "xmlns" {
+			kept = append(kept, attr)
+		}
+	}
+	return kept
+}
+
+// BetRadar does not follows the RFC3339 for Epoch Timestamps, they are
+// milliseconds since the Unix epoch. EpochTime converts them to UTC times
+// keeping the milliseconds, 0 is the zero time as it means not present.
+func EpochTime(ms int64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms).UTC()
+}
+
+// EpochMillis converts a time into a BetRadar epoch, the zero time is 0
+func EpochMillis(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
+
+// Epoch returns the Timestamp of the message
+func (t *BetRadarLiveOdds) Epoch() time.Time {
+	return EpochTime(t.Timestamp)
+}
+
+// SetEpoch sets the Timestamp of the message
+func (t *BetRadarLiveOdds) SetEpoch(epoch time.Time) {
+	t.Timestamp = EpochMillis(epoch)
+}
+
+// Start returns the StartTime of a cancelbet or undocancelbet period, the
+// zero time when the period has no start
+func (t *BetRadarLiveOdds) Start() time.Time {
+	return EpochTime(t.StartTime)
 }
 
-// BetRadar does not follows the RFC3339 for Epoch Timestamps
-func (t *BetRadarLiveOdds) Epoch() (epoch time.Time) {
-	epoch = time.Unix(t.Timestamp/1000, 0)
-	return epoch
+// SetStart sets the StartTime of the message
+func (t *BetRadarLiveOdds) SetStart(start time.Time) {
+	t.StartTime = EpochMillis(start)
+}
+
+// End returns the EndTime of a cancelbet or undocancelbet period, the zero
+// time when the period has no end
+func (t *BetRadarLiveOdds) End() time.Time {
+	return EpochTime(t.EndTime)
+}
+
+// SetEnd sets the EndTime of the message
+func (t *BetRadarLiveOdds) SetEnd(end time.Time) {
+	t.EndTime = EpochMillis(end)
 }
 
 type Match struct {
-	Active       bool   `xml:"active,attr"`
-	BetStatus    string `xml:"betstatus,attr,omitempty"`
-	MatchID      uint32 `xml:"matchid,attr"`
-	MatchTime    uint8  `xml:"matchtime,attr,omitempty"`
-	MsgNR        uint16 `xml:"msgnr,attr,omitempty"`
-	GameScore    string `xml:"gamescore,attr,omitempty"`
-	ClearedScore string `xml:"clearedscore,attr,omitempty"`
-	Score        string `xml:"score,attr,omitempty"`
-	Status       string `xml:"status,attr,omitempty"`
-	SetScores    string `xml:"setscores,attr,omitempty"`
+	Active       bool     `xml:"active,attr,omitempty"`
+	BetStatus    string   `xml:"betstatus,attr,omitempty"`
+	MatchID      uint32   `xml:"matchid,attr"`
+	MatchTime    uint16   `xml:"matchtime,attr,omitempty"`
+	MsgNR        uint32   `xml:"msgnr,attr,omitempty"`
+	GameScore    string   `xml:"gamescore,attr,omitempty"`
+	ClearedScore string   `xml:"clearedscore,attr,omitempty"`
+	Score        string   `xml:"score,attr,omitempty"`
+	Status       string   `xml:"status,attr,omitempty"`
+	SetScores    string   `xml:"setscores,attr,omitempty"`
+	Server       uint8    `xml:"server,attr,omitempty"`   // tennis: 1 home, 2 away
+	Tiebreak     bool     `xml:"tiebreak,attr,omitempty"` // tennis
+	SetScore     []string `xml:"-"`                       // tennis: setscoreN attributes
 	Odds         []Odd
 	Card         []Card
 	Scores       []Score   `xml:"Score"`
 	MatchInfo    MatchInfo `xml:"MatchInfo"`
+
+	ExtraAttrs    []xml.Attr   `xml:",any,attr"`
+	ExtraElements []RawElement `xml:",any"`
+
+	forms attrForms
+}
+
+// maxSetScores is the highest N taken from a setscoreN attribute, a tennis
+// match has 5 sets at most. Greater ones stay in ExtraAttrs so a bogus
+// setscore4000000000 does not allocate a huge SetScore.
+const maxSetScores = 9
+
+// matchAttrs are the attributes of a Match whose form is kept by attrForms
+var matchAttrs = []string{"active", "tiebreak", "matchtime", "server"}
+
+// UnmarshalXML decodes the Match as usual and then collects the setscoreN
+// attributes of tennis matches in SetScore, SetScore[0] is setscore1 and so
+// on. Sets that are not present in the message are left empty.
+func (m *Match) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type match Match // same fields without the UnmarshalXML method
+	if err := d.DecodeElement((*match)(m), &start); err != nil {
+		return err
+	}
+
+	m.SetScore = nil
+	var extra []xml.Attr
+	for _, attr := range m.ExtraAttrs {
+		n := setScoreN(attr.Name)
+		if n == 0 {
+			extra = append(extra, attr)
+			continue
+		}
+		for len(m.SetScore) < n {
+			m.SetScore = append(m.SetScore, "")
+		}
+		m.SetScore[n-1] = attr.Value
+	}
+	m.ExtraAttrs = extra
+	m.forms = readAttrForms(&start, matchAttrs)
+	return nil
+}
+
+// MarshalXML writes the SetScore back as setscoreN attributes and the
+// booleans and zero numbers as they came in the message
+func (m Match) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type match Match // same fields without the MarshalXML method
+	v := match(m)
+	v.Active, v.Tiebreak = false, false // written by the attrForms
+	start.Attr = append(start.Attr, m.forms.boolAttr(matchAttrs, "active", m.Active, false)...)
+	start.Attr = append(start.Attr, m.forms.boolAttr(matchAttrs, "tiebreak", m.Tiebreak, false)...)
+	start.Attr = append(start.Attr, m.forms.zeroAttr(matchAttrs, "matchtime", m.MatchTime == 0)...)
+	start.Attr = append(start.Attr, m.forms.zeroAttr(matchAttrs, "server", m.Server == 0)...)
+	v.ExtraAttrs = append([]xml.Attr(nil), m.ExtraAttrs...)
+	for i, set := range m.SetScore {
+		if set != "" {
+			name := xml.Name{Local: "setscore" + strconv.Itoa(i+1)}
+			v.ExtraAttrs = append(v.ExtraAttrs, xml.Attr{Name: name, Value: set})
+		}
+	}
+	return e.EncodeElement(v, start)
+}
+
+// hasActive reports whether the active attribute is set, false when it was
+// not in the message
+func (m *Match) hasActive() bool {
+	return m.Active || m.forms.has(matchAttrs, "active")
+}
+
+// setScoreN returns N for a setscoreN attribute name and 0 for any other,
+// including the ones whose N is greater than maxSetScores
+func setScoreN(name xml.Name) int {
+	if name.Space != "" || !strings.HasPrefix(name.Local, "setscore") {
+		return 0
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(name.Local, "setscore"))
+	if err != nil || n < 1 || n > maxSetScores {
+		return 0
+	}
+	return n
 }
 
 type MatchInfo struct {
-	DateOfMatch int64      `xml:"DateOfMatch"`
-	Sport       Sport      `xml:"Sport"`
-	Category    Category   `xml:"Category"`
-	Tournament  Tournament `xml:"Tournament"`
-	HomeTeam    HomeTeam   `xml:"HomeTeam"`
-	AwayTeam    AwayTeam   `xml:"AwayTeam"`
+	DateOfMatch  int64        `xml:"DateOfMatch"`
+	Sport        Sport        `xml:"Sport"`
+	Category     Category     `xml:"Category"`
+	Tournament   Tournament   `xml:"Tournament"`
+	HomeTeam     HomeTeam     `xml:"HomeTeam"`
+	AwayTeam     AwayTeam     `xml:"AwayTeam"`
+	TvChannels   []TvChannel  `xml:"TvChannels>TvChannel"`
+	ExtraInfo    []Info       `xml:"ExtraInfo>Info"`
+	CoverageInfo CoverageInfo `xml:"CoverageInfo"`
+}
+
+// Date returns the DateOfMatch as a time
+func (mi *MatchInfo) Date() time.Time {
+	return EpochTime(mi.DateOfMatch)
+}
+
+// SetDate sets the DateOfMatch
+func (mi *MatchInfo) SetDate(date time.Time) {
+	mi.DateOfMatch = EpochMillis(date)
+}
+
+// Extra returns the value of the ExtraInfo item with the given key
+func (mi *MatchInfo) Extra(key string) (string, bool) {
+	for _, info := range mi.ExtraInfo {
+		if info.Key == key {
+			return info.Value, true
+		}
+	}
+	return "", false
+}
+
+// empty reports whether the MatchInfo was not present in the message
+func (mi *MatchInfo) empty() bool {
+	return mi.DateOfMatch == 0 &&
+		mi.Sport == (Sport{}) &&
+		mi.Category == (Category{}) &&
+		mi.Tournament == (Tournament{}) &&
+		mi.HomeTeam == (HomeTeam{}) &&
+		mi.AwayTeam == (AwayTeam{}) &&
+		len(mi.TvChannels) == 0 &&
+		len(mi.ExtraInfo) == 0 &&
+		mi.CoverageInfo.empty()
+}
+
+// MarshalXML omits the MatchInfo element when it is empty, it is only sent
+// by BetRadar in meta messages and it must never be part of a request
+func (mi MatchInfo) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if mi.empty() {
+		return nil
+	}
+
+	type matchInfo MatchInfo // same fields without the MarshalXML method
+	return e.EncodeElement(matchInfo(mi), start)
 }
 
 type Sport struct {
 	Value string `xml:",chardata"`
-	Id    uint8  `xml:"id,attr"`
+	Id    uint32 `xml:"id,attr"`
 }
 
 type Category struct {
 	Value string `xml:",chardata"`
-	Id    uint16 `xml:"id,attr"`
+	Id    uint32 `xml:"id,attr"`
 }
 
 type Tournament struct {
@@ -97,17 +323,106 @@ type AwayTeam struct {
 	Id    uint32 `xml:"id,attr"`
 }
 
+// TvChannel is a TV channel that broadcasts the match
+type TvChannel struct {
+	Value     string `xml:",chardata"`
+	Id        uint32 `xml:"id,attr,omitempty"`
+	StartTime int64  `xml:"starttime,attr,omitempty"`
+}
+
+// Info is a key/value item of the match ExtraInfo, like the neutral ground
+// or the number of periods
+type Info struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// CoverageInfo tells how BetRadar covers the match
+type CoverageInfo struct {
+	Level        string     `xml:"level,attr,omitempty"`
+	LiveCoverage bool       `xml:"livecoverage,attr,omitempty"` // covered from the venue
+	Streaming    bool       `xml:"streaming,attr,omitempty"`    // live streaming available
+	Coverage     []Coverage `xml:"Coverage"`
+
+	forms attrForms
+}
+
+var coverageInfoAttrs = []string{"livecoverage", "streaming"}
+
+func (c *CoverageInfo) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type coverageInfo CoverageInfo // same fields without the UnmarshalXML method
+	if err := d.DecodeElement((*coverageInfo)(c), &start); err != nil {
+		return err
+	}
+	c.forms = readAttrForms(&start, coverageInfoAttrs)
+	return nil
+}
+
+// MarshalXML writes the booleans as they came in the message
+func (c CoverageInfo) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type coverageInfo CoverageInfo // same fields without the MarshalXML method
+	v := coverageInfo(c)
+	v.LiveCoverage, v.Streaming = false, false // written by the attrForms
+	start.Attr = append(start.Attr, c.forms.boolAttr(coverageInfoAttrs, "livecoverage", c.LiveCoverage, false)...)
+	start.Attr = append(start.Attr, c.forms.boolAttr(coverageInfoAttrs, "streaming", c.Streaming, false)...)
+	return e.EncodeElement(v, start)
+}
+
+// Coverage is a single feature covered for the match, like "basic_score"
+type Coverage struct {
+	Includes string `xml:"includes,attr"`
+}
+
+// Includes reports whether the given feature is covered
+func (c *CoverageInfo) Includes(feature string) bool {
+	for _, coverage := range c.Coverage {
+		if coverage.Includes == feature {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *CoverageInfo) empty() bool {
+	return c.Level == "" && !c.LiveCoverage && !c.Streaming && len(c.Coverage) == 0
+}
+
 type Odd struct {
 	OddsID           uint32 `xml:"id,attr"`
-	Active           bool   `xml:"active,attr"`
-	Changed          string `xml:"changed,attr"`
+	Active           bool   `xml:"active,attr,omitempty"` // always written by MarshalXML
+	Changed          string `xml:"changed,attr,omitempty"`
 	Combination      uint8  `xml:"combination,attr"`
-	FreeText         string `xml:"freetext,attr"`
-	SpecialOddsValue string `xml:"specialoddsvalue,attr"`
-	SubType          uint16 `xml:"subtype,attr"`
+	FreeText         string `xml:"freetext,attr,omitempty"`
+	SpecialOddsValue string `xml:"specialoddsvalue,attr,omitempty"`
+	SubType          uint16 `xml:"subtype,attr,omitempty"`
 	Type             string `xml:"type,attr"`
 	TypeID           uint16 `xml:"typeid,attr"`
 	OddsField        []OddsField
+
+	ExtraAttrs    []xml.Attr   `xml:",any,attr"`
+	ExtraElements []RawElement `xml:",any"`
+
+	forms attrForms
+}
+
+var oddAttrs = []string{"active"}
+
+func (o *Odd) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type odd Odd // same fields without the UnmarshalXML method
+	if err := d.DecodeElement((*odd)(o), &start); err != nil {
+		return err
+	}
+	o.forms = readAttrForms(&start, oddAttrs)
+	return nil
+}
+
+// MarshalXML writes active as it came in the message, 1 or 0 by default
+func (o Odd) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type odd Odd // same fields without the MarshalXML method
+	v := odd(o)
+	v.Active = false // written by the attrForms
+	start.Attr = append(start.Attr, o.forms.boolAttr(oddAttrs, "active", o.Active, true)...)
+	return e.EncodeElement(v, start)
 }
 
 type OddsField struct {
@@ -115,6 +430,40 @@ type OddsField struct {
 	Active  bool   `xml:"active,attr,omitempty"`
 	Outcome bool   `xml:"outcome,attr,omitempty"`
 	Type    string `xml:"type,attr"`
+
+	ExtraAttrs    []xml.Attr   `xml:",any,attr"`
+	ExtraElements []RawElement `xml:",any"`
+
+	forms attrForms
+}
+
+var oddsFieldAttrs = []string{"active", "outcome"}
+
+// UnmarshalXML keeps how the active and outcome attributes came, so a 0 is
+// not lost on marshal because of the omitempty
+func (f *OddsField) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type oddsField OddsField // same fields without the UnmarshalXML method
+	if err := d.DecodeElement((*oddsField)(f), &start); err != nil {
+		return err
+	}
+	f.forms = readAttrForms(&start, oddsFieldAttrs)
+	return nil
+}
+
+// MarshalXML writes active and outcome as they came in the message
+func (f OddsField) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type oddsField OddsField // same fields without the MarshalXML method
+	v := oddsField(f)
+	v.Active, v.Outcome = false, false // written by the attrForms
+	start.Attr = append(start.Attr, f.forms.boolAttr(oddsFieldAttrs, "active", f.Active, false)...)
+	start.Attr = append(start.Attr, f.forms.boolAttr(oddsFieldAttrs, "outcome", f.Outcome, false)...)
+	return e.EncodeElement(v, start)
+}
+
+// hasOutcome reports whether the outcome attribute is set, false when it
+// was not in the message as in change messages
+func (f *OddsField) hasOutcome() bool {
+	return f.Outcome || f.forms.has(oddsFieldAttrs, "outcome")
 }
 
 type OddsType struct {
@@ -139,24 +488,142 @@ type Card struct {
 	CardID uint32 `xml:"id,attr"`
 	Player string `xml:"player,attr"`
 	Team   string `xml:"team,attr"`
-	Time   uint8  `xml:"time,attr"`
+	Time   uint16 `xml:"time,attr"`
 	Type   string `xml:"type,attr"`
 }
 
 type Score struct {
 	ScoreID     uint32 `xml:"id,attr"`
-	Away        bool   `xml:"away,attr"`
-	Home        bool   `xml:"home,attr"`
+	Away        bool   `xml:"away,attr,omitempty"` // always written by MarshalXML
+	Home        bool   `xml:"home,attr,omitempty"` // always written by MarshalXML
 	Player      string `xml:"player,attr,omitempty"`
 	ScoringTeam string `xml:"scoringteam,attr"`
-	Time        int8   `xml:"time,attr"`
+	Time        int16  `xml:"time,attr"` // -1 when unknown
 	Type        string `xml:"type,attr"`
+
+	forms attrForms
+}
+
+var scoreAttrs = []string{"away", "home"}
+
+func (sc *Score) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type score Score // same fields without the UnmarshalXML method
+	if err := d.DecodeElement((*score)(sc), &start); err != nil {
+		return err
+	}
+	sc.forms = readAttrForms(&start, scoreAttrs)
+	return nil
 }
 
+// MarshalXML writes away and home as they came in the message, 1 or 0 by
+// default
+func (sc Score) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type score Score // same fields without the MarshalXML method
+	v := score(sc)
+	v.Away, v.Home = false, false // written by the attrForms
+	start.Attr = append(start.Attr, sc.forms.boolAttr(scoreAttrs, "away", sc.Away, true)...)
+	start.Attr = append(start.Attr, sc.forms.boolAttr(scoreAttrs, "home", sc.Home, true)...)
+	return e.EncodeElement(v, start)
+}
+
+// BookMakerStatus is the message that bookmakers send to BetRadar to login
+// and to operate over the feed, BetRadar uses it as well to reply the login
 type BookMakerStatus struct {
-	XMLName     xml.Name `xml:"BookMakerStatus"`
-	Timestamp   int64    `xml:"timestamp,attr"`
-	Type        string   `xml:"type,attr"`
-	BookmakerID uint16   `xml:"bookmakerid,attr"`
-	Match       []Match  `xml:"Match,omitempty"`
+	XMLName      xml.Name `xml:"BookmakerStatus"`
+	Timestamp    int64    `xml:"timestamp,attr"`
+	Type         string   `xml:"type,attr"`
+	BookmakerID  uint16   `xml:"bookmakerid,attr"`
+	Key          string   `xml:"key,attr,omitempty"`
+	HoursBack    uint16   `xml:"hoursback,attr,omitempty"`
+	HoursForward uint16   `xml:"hoursforward,attr,omitempty"`
+	ReplyNr      uint32   `xml:"replynr,attr,omitempty"`
+	Match        []Match  `xml:"Match,omitempty"`
+	Text         string   `xml:",chardata"` // reason of an error reply
+}
+
+// Epoch returns the Timestamp of the status
+func (s *BookMakerStatus) Epoch() time.Time {
+	return EpochTime(s.Timestamp)
+}
+
+// SetEpoch sets the Timestamp of the status
+func (s *BookMakerStatus) SetEpoch(epoch time.Time) {
+	s.Timestamp = EpochMillis(epoch)
+}
+
+// RawElement keeps an element that this package does not know about, so it
+// is not lost when the message is marshaled again
+type RawElement struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr   `xml:",any,attr"`
+	Text     string       `xml:",chardata"`
+	Children []RawElement `xml:",any"`
+}
+
+// attrForms remembers how some known attributes of an element came in the
+// message, so they are marshaled back the same way. BetRadar writes most of
+// the booleans as 1 or 0, a few like tiebreak as true or false, and some
+// omitempty numbers like matchtime can be a legitimate 0. Bit i is about the
+// attribute names[i] of the element.
+type attrForms struct {
+	present uint8 // the attribute was in the message
+	words   uint8 // the boolean was written as true or false
+}
+
+func readAttrForms(start *xml.StartElement, names []string) attrForms {
+	var f attrForms
+	for _, attr := range start.Attr {
+		if attr.Name.Space != "" {
+			continue
+		}
+		if i := attrIndex(names, attr.Name.Local); i >= 0 {
+			f.present |= 1 << i
+			switch strings.ToLower(strings.TrimSpace(attr.Value)) {
+			case "true", "false":
+				f.words |= 1 << i
+			}
+		}
+	}
+	return f
+}
+
+func attrIndex(names []string, name string) int {
+	for i, n := range names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// has reports whether the attribute was in the message
+func (f attrForms) has(names []string, name string) bool {
+	i := attrIndex(names, name)
+	return i >= 0 && f.present&(1<<i) != 0
+}
+
+// boolAttr returns the boolean attribute with the given value, as 1 or 0
+// unless it came as true or false. A false that was not in the message is
+// only written when always is set.
+func (f attrForms) boolAttr(names []string, name string, value, always bool) []xml.Attr {
+	if !value && !always && !f.has(names, name) {
+		return nil
+	}
+	s := "0"
+	if value {
+		s = "1"
+	}
+	if i := attrIndex(names, name); i >= 0 && f.words&(1<<i) != 0 {
+		s = strconv.FormatBool(value)
+	}
+	return []xml.Attr{{Name: xml.Name{Local: name}, Value: s}}
+}
+
+// zeroAttr returns the attribute with a 0 when its number is zero and it
+// was in the message, the encoder leaves it out because of the omitempty
+func (f attrForms) zeroAttr(names []string, name string, zero bool) []xml.Attr {
+	if !zero || !f.has(names, name) {
+		return nil
+	}
+	return []xml.Attr{{Name: xml.Name{Local: name}, Value: "0"}}
 }
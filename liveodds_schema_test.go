@@ -1,8 +1,12 @@
 package liveodds
 
 import (
+	"bytes"
 	"encoding/xml"
+	"io"
 	"io/ioutil"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 )
@@ -41,12 +45,19 @@ func TestSimpleAlive(t *testing.T) {
 
 	feed := LoadXMLFixture("fixtures/alive.xml")
 
-	ti := time.Date(2013, time.December, 15, 17, 56, 30, 0, time.Local)
+	ti := time.Date(2013, time.December, 15, 17, 56, 30, 452*int(time.Millisecond), time.UTC)
 	var xmlTests = []xmlTest{
 		{feed.Status, "alive"},
 		{len(feed.Matches), 1},
 		{ti, feed.Epoch()},
+		{feed.Epoch().Location(), time.UTC},
 		{feed.XMLNS, "http://www.betradar.com/BetradarLiveOdds"},
+		{feed.Matches[0].Server, uint8(1)},
+		{feed.Matches[0].Tiebreak, false},
+		{feed.Matches[0].Status, "2set"},
+		{len(feed.Matches[0].SetScore), 2},
+		{feed.Matches[0].SetScore[0], "6:3"},
+		{feed.Matches[0].SetScore[1], "1:3"},
 	}
 
 	for _, tt := range xmlTests {
@@ -57,6 +68,31 @@ func TestSimpleAlive(t *testing.T) {
 
 }
 
+func TestSetScoreLimit(t *testing.T) {
+	msg := `<Match matchid="1" setscore1="6:3" setscore9="1:0" setscore10="2:0" setscore4000000000="3:0"/>`
+
+	m := Match{}
+	check(xml.Unmarshal([]byte(msg), &m))
+	output, err := xml.Marshal(&m)
+	check(err)
+
+	var xmlTests = []xmlTest{
+		{len(m.SetScore), 9},
+		{m.SetScore[0], "6:3"},
+		{m.SetScore[8], "1:0"},
+		{len(m.ExtraAttrs), 2},
+		{m.ExtraAttrs[0].Name.Local, "setscore10"},
+		{m.ExtraAttrs[1].Value, "3:0"},
+		{strings.Contains(string(output), `setscore4000000000="3:0"`), true},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestSetScoreLimit", tt.expected, tt.n)
+		}
+	}
+}
+
 func TestSimpleChange(t *testing.T) {
 
 	feed := LoadXMLFixture("fixtures/change.xml")
@@ -66,7 +102,7 @@ func TestSimpleChange(t *testing.T) {
 		{feed.Matches[0].Active, true},
 		{feed.Matches[0].BetStatus, "stopped"},
 		{feed.Matches[0].MatchID, uint32(867278)},
-		{feed.Matches[0].MsgNR, uint16(2)},
+		{feed.Matches[0].MsgNR, uint32(2)},
 		{feed.Matches[0].Score, "-:-"},
 		{feed.Matches[0].Status, "not_started"},
 		{len(feed.Matches[0].Odds), 5},
@@ -239,8 +275,8 @@ func TestSimpleScore(t *testing.T) {
 		{len(feed.Matches), 1},
 		{feed.Matches[0].Active, true},
 		{feed.Matches[0].BetStatus, "stopped"},
-		{feed.Matches[0].MatchTime, uint8(3)},
-		{feed.Matches[0].MsgNR, uint16(10)},
+		{feed.Matches[0].MatchTime, uint16(3)},
+		{feed.Matches[0].MsgNR, uint32(10)},
 		{feed.Matches[0].Score, "0:1"},
 		{feed.Matches[0].SetScores, "0:1"},
 		{feed.Matches[0].Status, "1p"},
@@ -250,7 +286,7 @@ func TestSimpleScore(t *testing.T) {
 		{feed.Matches[0].Scores[0].ScoreID, uint32(66664)},
 		{feed.Matches[0].Scores[0].Player, ""},
 		{feed.Matches[0].Scores[0].ScoringTeam, "away"},
-		{feed.Matches[0].Scores[0].Time, int8(-1)},
+		{feed.Matches[0].Scores[0].Time, int16(-1)},
 		{feed.Matches[0].Scores[0].Type, "live"},
 	}
 
@@ -265,6 +301,8 @@ func TestSimpleCard(t *testing.T) {
 	feed := LoadXMLFixture("fixtures/card.xml")
 	var xmlTests = []xmlTest{
 		{feed.Status, "score"},
+		{feed.ReplyType, "scoreandcardsummary"},
+		{feed.ReplyNr, uint32(1)},
 		{len(feed.Matches), 1},
 		{feed.Matches[0].Active, true},
 		{feed.Matches[0].BetStatus, "stopped"},
@@ -275,12 +313,12 @@ func TestSimpleCard(t *testing.T) {
 		{feed.Matches[0].Card[0].CardID, uint32(111556)},
 		{feed.Matches[0].Card[0].Player, "Ramires"},
 		{feed.Matches[0].Card[0].Team, "home"},
-		{feed.Matches[0].Card[0].Time, uint8(70)},
+		{feed.Matches[0].Card[0].Time, uint16(70)},
 		{feed.Matches[0].Card[0].Type, "yellow"},
 		{feed.Matches[0].Card[1].CardID, uint32(111555)},
 		{feed.Matches[0].Card[1].Player, "Fuentes, Ismael"},
 		{feed.Matches[0].Card[1].Team, "away"},
-		{feed.Matches[0].Card[1].Time, uint8(67)},
+		{feed.Matches[0].Card[1].Time, uint16(67)},
 		{feed.Matches[0].Card[1].Type, "yellow"},
 	}
 
@@ -299,7 +337,7 @@ func TestSimpleBetStart(t *testing.T) {
 		{feed.Matches[0].Active, true},
 		{feed.Matches[0].BetStatus, "started"},
 		{feed.Matches[0].MatchID, uint32(935449)},
-		{feed.Matches[0].MsgNR, uint16(2)},
+		{feed.Matches[0].MsgNR, uint32(2)},
 		{feed.Matches[0].Score, ":"},
 		{feed.Matches[0].Status, "not_started"},
 	}
@@ -319,9 +357,10 @@ func TestSimpleBetStop(t *testing.T) {
 		{feed.Matches[0].Active, true},
 		{feed.Matches[0].BetStatus, "stopped"},
 		{feed.Matches[0].MatchID, uint32(935449)},
-		{feed.Matches[0].MsgNR, uint16(51)},
+		{feed.Matches[0].MsgNR, uint32(51)},
 		{feed.Matches[0].Score, "0:1"},
 		{feed.Matches[0].SetScores, "0:1 - 0:0"},
+		{len(feed.Matches[0].SetScore), 0},
 		{feed.Matches[0].Status, "ended"},
 	}
 
@@ -340,8 +379,8 @@ func TestSimpleCancelBet(t *testing.T) {
 		{feed.Matches[0].Active, true},
 		{feed.Matches[0].BetStatus, "stopped"},
 		{feed.Matches[0].MatchID, uint32(661373)},
-		{feed.Matches[0].MatchTime, uint8(9)},
-		{feed.Matches[0].MsgNR, uint16(46)},
+		{feed.Matches[0].MatchTime, uint16(9)},
+		{feed.Matches[0].MsgNR, uint32(46)},
 		{feed.Matches[0].Status, "1p"},
 		{len(feed.Matches[0].Odds), 1},
 		{feed.Matches[0].Odds[0].Active, true},
@@ -371,8 +410,8 @@ func TestSimpleCancelBetWithPeriod(t *testing.T) {
 		{feed.Matches[0].Active, true},
 		{feed.Matches[0].BetStatus, "stopped"},
 		{feed.Matches[0].MatchID, uint32(661373)},
-		{feed.Matches[0].MatchTime, uint8(9)},
-		{feed.Matches[0].MsgNR, uint16(46)},
+		{feed.Matches[0].MatchTime, uint16(9)},
+		{feed.Matches[0].MsgNR, uint32(46)},
 		{feed.Matches[0].Status, "1p"},
 		{len(feed.Matches[0].Odds), 1},
 		{feed.Matches[0].Odds[0].Active, true},
@@ -400,7 +439,7 @@ func TestSimpleUndoCancelBet(t *testing.T) {
 		{feed.Matches[0].Active, true},
 		{feed.Matches[0].BetStatus, "stopped"},
 		{feed.Matches[0].MatchID, uint32(661373)},
-		{feed.Matches[0].MsgNR, uint16(49)},
+		{feed.Matches[0].MsgNR, uint32(49)},
 		{feed.Matches[0].Status, "not_started"},
 		{len(feed.Matches[0].Odds), 1},
 		{feed.Matches[0].Odds[0].Active, true},
@@ -428,8 +467,8 @@ func TestSimpleRolback(t *testing.T) {
 		{feed.Matches[0].Active, true},
 		{feed.Matches[0].BetStatus, "stopped"},
 		{feed.Matches[0].MatchID, uint32(793862)},
-		{feed.Matches[0].MatchTime, uint8(1)},
-		{feed.Matches[0].MsgNR, uint16(10)},
+		{feed.Matches[0].MatchTime, uint16(1)},
+		{feed.Matches[0].MsgNR, uint32(10)},
 		{feed.Matches[0].Status, "1p"},
 		{len(feed.Matches[0].Odds), 1},
 		{feed.Matches[0].Odds[0].Active, true},
@@ -464,8 +503,8 @@ func TestSimpleClearBet(t *testing.T) {
 		{feed.Matches[0].BetStatus, "stopped"},
 		{feed.Matches[0].ClearedScore, "0:0"},
 		{feed.Matches[0].MatchID, uint32(793862)},
-		{feed.Matches[0].MatchTime, uint8(1)},
-		{feed.Matches[0].MsgNR, uint16(8)},
+		{feed.Matches[0].MatchTime, uint16(1)},
+		{feed.Matches[0].MsgNR, uint32(8)},
 		{feed.Matches[0].Status, "1p"},
 		{len(feed.Matches[0].Odds), 1},
 		{feed.Matches[0].Odds[0].Active, true},
@@ -491,6 +530,34 @@ func TestSimpleClearBet(t *testing.T) {
 	}
 }
 
+func TestIntegerBoundaries(t *testing.T) {
+	feed := LoadXMLFixture("fixtures/boundaries.xml")
+	max, over := feed.Matches[0], feed.Matches[1]
+
+	var xmlTests = []xmlTest{
+		{max.MatchID, uint32(4294967295)},
+		{max.MatchTime, uint16(65535)},
+		{max.MsgNR, uint32(4294967295)},
+		{max.Card[0].Time, uint16(65535)},
+		{max.Scores[0].Time, int16(32767)},
+		{max.Scores[1].Time, int16(-1)},
+		{max.MatchInfo.Sport.Id, uint32(4294967295)},
+		{max.MatchInfo.Category.Id, uint32(4294967295)},
+		{over.MatchTime, uint16(256)},
+		{over.MsgNR, uint32(65536)},
+		{over.Card[0].Time, uint16(256)},
+		{over.Scores[0].Time, int16(128)},
+		{over.MatchInfo.Sport.Id, uint32(256)},
+		{over.MatchInfo.Category.Id, uint32(65536)},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestIntegerBoundaries", tt.expected, tt.n)
+		}
+	}
+}
+
 func TestSimpleRegisterReply(t *testing.T) {
 	feed := LoadXMLFixture("fixtures/registerreply.xml")
 	var xmlTests = []xmlTest{
@@ -501,11 +568,12 @@ func TestSimpleRegisterReply(t *testing.T) {
 		{feed.Matches[0].MatchID, uint32(935448)},
 		{feed.Matches[0].Status, "1p"},
 		{feed.Matches[0].MatchInfo.DateOfMatch, int64(1275051158000)},
-		{feed.Matches[0].MatchInfo.Sport.Id, uint8(1)},
-		{feed.Matches[0].MatchInfo.Category.Id, uint16(94)},
+		{feed.Matches[0].MatchInfo.Sport.Id, uint32(1)},
+		{feed.Matches[0].MatchInfo.Category.Id, uint32(94)},
 		{feed.Matches[0].MatchInfo.Tournament.Id, uint32(5956)},
 		{feed.Matches[0].MatchInfo.HomeTeam.Id, uint32(773839)},
 		{feed.Matches[0].MatchInfo.AwayTeam.Id, uint32(1013871)},
+		{len(feed.Matches[0].MatchInfo.TvChannels), 0},
 	}
 
 	for _, tt := range xmlTests {
@@ -515,10 +583,78 @@ func TestSimpleRegisterReply(t *testing.T) {
 	}
 }
 
+func TestEpochAccessors(t *testing.T) {
+	feed := LoadXMLFixture("fixtures/cancelbet_with_period.xml")
+	start := time.Date(2008, time.January, 4, 8, 38, 22, 0, time.UTC)
+	end := time.Date(2008, time.January, 4, 8, 40, 22, 222*int(time.Millisecond), time.UTC)
+
+	var xmlTests = []xmlTest{
+		{feed.Start(), start},
+		{feed.End(), end},
+		{feed.Epoch().Nanosecond(), 18 * int(time.Millisecond)},
+		{EpochTime(0).IsZero(), true},
+		{EpochMillis(time.Time{}), int64(0)},
+		{EpochMillis(end), int64(1199436022222)},
+		{EpochTime(1199436022222), end},
+	}
+
+	// marshaling writes the times back as BetRadar epochs
+	msg := BetRadarLiveOdds{Status: "cancelbet"}
+	msg.SetEpoch(end.Local())
+	msg.SetStart(start)
+	output, err := msg.Marshal()
+	check(err)
+	expected := `<BetradarLiveOdds status="cancelbet" timestamp="1199436022222" starttime="1199435902000" xmlns="` + Namespace + `"></BetradarLiveOdds>`
+	xmlTests = append(xmlTests, xmlTest{string(output), expected})
+
+	info := MatchInfo{}
+	info.SetDate(start)
+	xmlTests = append(xmlTests, xmlTest{info.DateOfMatch, int64(1199435902000)}, xmlTest{info.Date(), start})
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestEpochAccessors", tt.expected, tt.n)
+		}
+	}
+}
+
+func TestSimpleMatchInfo(t *testing.T) {
+	feed := LoadXMLFixture("fixtures/matchinfo.xml")
+	info := feed.Matches[0].MatchInfo
+	neutral, neutralOk := info.Extra("neutralground")
+	_, missingOk := info.Extra("missing")
+
+	ti := time.Date(2013, time.November, 7, 16, 0, 0, 0, time.UTC)
+	var xmlTests = []xmlTest{
+		{feed.ReplyType, "matchlist"},
+		{info.Date(), ti},
+		{info.HomeTeam.Value, "Chelsea"},
+		{len(info.TvChannels), 2},
+		{info.TvChannels[0], TvChannel{"Sky Sports 1", 12, 1383839700000}},
+		{info.TvChannels[1], TvChannel{"BBC One", 37, 0}},
+		{len(info.ExtraInfo), 2},
+		{info.ExtraInfo[1], Info{"periodlength", "45"}},
+		{neutral, "0"},
+		{neutralOk, true},
+		{missingOk, false},
+		{info.CoverageInfo.Level, "gold"},
+		{info.CoverageInfo.LiveCoverage, true},
+		{info.CoverageInfo.Streaming, true},
+		{info.CoverageInfo.Includes("key_events"), true},
+		{info.CoverageInfo.Includes("deeper_analysis"), false},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestSimpleMatchInfo", tt.expected, tt.n)
+		}
+	}
+}
+
 func TestBookMakerstatus(t *testing.T) {
 	v := &BookMakerStatus{Type: "error", BookmakerID: 1234}
-	v.Timestamp = time.Date(
-		2013, time.December, 15, 17, 56, 30, 0, time.Local).Unix()
+	ti := time.Date(2013, time.December, 15, 17, 56, 30, 452*int(time.Millisecond), time.UTC)
+	v.SetEpoch(ti.In(time.FixedZone("CET", 3600)))
 
 	output, err := xml.MarshalIndent(v, " ", "    ")
 	if err != nil {
@@ -531,7 +667,7 @@ func TestBookMakerstatus(t *testing.T) {
 		panic(unmarshal_err)
 	}
 
-	if feed.Timestamp != v.Timestamp || feed.BookmakerID != v.BookmakerID {
+	if feed.Epoch() != ti || feed.BookmakerID != v.BookmakerID {
 		t.Errorf("%v and %v are not equal", feed, v)
 	}
 
@@ -552,3 +688,182 @@ func TestBookMakerstatus(t *testing.T) {
 		t.Error("Matches ID does not match")
 	}
 }
+
+// elementAttributes returns every element of the XML document that has
+// attributes with its attributes sorted by name, ignoring the namespace
+// declarations and the empty attributes. The elements are sorted as well as the encoder writes them
+// in the order of the struct fields.
+func elementAttributes(data []byte) (elements []string) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			sort.Strings(elements)
+			return elements
+		}
+		check(err)
+
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		var attrs []string
+		for _, attr := range start.Attr {
+			if attr.Name.Local == "xmlns" || attr.Name.Space == "xmlns" || attr.Value == "" {
+				continue
+			}
+			attrs = append(attrs, attr.Name.Local+"="+attr.Value)
+		}
+		if len(attrs) > 0 {
+			sort.Strings(attrs)
+			elements = append(elements, start.Name.Local+" "+strings.Join(attrs, " "))
+		}
+	}
+}
+
+func TestRoundTripAttributes(t *testing.T) {
+	fixtures := []string{
+		"fixtures/alive.xml",
+		"fixtures/asianhandicap.xml",
+		"fixtures/betstart.xml",
+		"fixtures/betstop.xml",
+		"fixtures/card.xml",
+		"fixtures/cancelbet.xml",
+		"fixtures/boundaries.xml",
+		"fixtures/cancelbet_with_period.xml",
+		"fixtures/change.xml",
+		"fixtures/clearbet.xml",
+		"fixtures/matchinfo.xml",
+		"fixtures/registerreply.xml",
+		"fixtures/rollback.xml",
+		"fixtures/score.xml",
+		"fixtures/translation.xml",
+		"fixtures/undocancelbet.xml",
+	}
+
+	for _, fixture := range fixtures {
+		msg, err := ioutil.ReadFile(fixture)
+		check(err)
+
+		feed := LoadXMLFixture(fixture)
+		output, err := xml.Marshal(&feed)
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+			continue
+		}
+
+		expected, current := elementAttributes(msg), elementAttributes(output)
+		if len(expected) != len(current) {
+			t.Errorf(failed_msg, fixture, expected, current)
+			continue
+		}
+		for i := range expected {
+			if expected[i] != current[i] {
+				t.Errorf(failed_msg, fixture, expected[i], current[i])
+			}
+		}
+	}
+}
+
+func TestRoundTripUnknownElements(t *testing.T) {
+	msg := `<BetradarLiveOdds status="change" timestamp="1" newattr="x">
+    <Match active="1" matchid="1" tiebreak="false">
+        <Odds active="1" id="2" typeid="6" type="ft3w" margin="5">
+            <OddsField active="0" outcome="0" type="1" probability="0.4">1.5</OddsField>
+            <Probability value="0.4"/>
+        </Odds>
+        <Weather kind="rain"><Wind speed="10">strong</Wind></Weather>
+    </Match>
+    <Extra/>
+</BetradarLiveOdds>`
+
+	feed := BetRadarLiveOdds{}
+	check(xml.Unmarshal([]byte(msg), &feed))
+	output, err := xml.Marshal(&feed)
+	check(err)
+	again := BetRadarLiveOdds{}
+	check(xml.Unmarshal(output, &again))
+
+	m := again.Matches[0]
+	var xmlTests = []xmlTest{
+		{again.ExtraAttrs[0].Value, "x"},
+		{again.ExtraElements[0].XMLName.Local, "Extra"},
+		{len(m.ExtraAttrs), 0},
+		{m.ExtraElements[0].XMLName.Local, "Weather"},
+		{m.ExtraElements[0].Attrs[0].Value, "rain"},
+		{m.ExtraElements[0].Children[0].Text, "strong"},
+		{m.Odds[0].ExtraAttrs[0].Value, "5"},
+		{m.Odds[0].ExtraElements[0].XMLName.Local, "Probability"},
+		{m.Odds[0].OddsField[0].Value, "1.5"},
+		{len(m.Odds[0].OddsField[0].ExtraAttrs), 1},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestRoundTripUnknownElements", tt.expected, tt.n)
+		}
+	}
+
+	// once the fields are set they are written once, in the same form
+	m.Tiebreak = true
+	m.Odds[0].OddsField[0].Outcome = true
+	output, err = xml.Marshal(&m)
+	check(err)
+	xmlTests = []xmlTest{
+		{strings.Count(string(output), "tiebreak="), 1},
+		{strings.Count(string(output), "outcome="), 1},
+		{strings.Contains(string(output), `tiebreak="true"`), true},
+		{strings.Contains(string(output), `outcome="1"`), true},
+		{strings.Contains(string(output), `active="0"`), true},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestRoundTripUnknownElements", tt.expected, tt.n)
+		}
+	}
+}
+
+func TestNamespace(t *testing.T) {
+	fixtures := []string{
+		"fixtures/alive.xml",    // default namespace
+		"fixtures/betstart.xml", // ns1: prefix
+		"fixtures/clearbet.xml", // ns1: prefix
+	}
+
+	for _, fixture := range fixtures {
+		feed := LoadXMLFixture(fixture)
+		output, err := feed.Marshal()
+		check(err)
+
+		again := BetRadarLiveOdds{}
+		check(xml.Unmarshal(output, &again))
+
+		var xmlTests = []xmlTest{
+			{feed.XMLNS, Namespace},
+			{len(feed.ExtraAttrs) == 0 || feed.ExtraAttrs[0].Name.Space != "xmlns", true},
+			{strings.HasPrefix(string(output), `<BetradarLiveOdds `), true},
+			{strings.Count(string(output), "xmlns"), 1},
+			{strings.Contains(string(output), `xmlns="`+Namespace+`"`), true},
+			{strings.Contains(string(output), "ns1"), false},
+			{again.XMLNS, Namespace},
+			{len(again.Matches), len(feed.Matches)},
+			{again.Matches[0].MatchID, feed.Matches[0].MatchID},
+		}
+
+		for _, tt := range xmlTests {
+			if tt.n != tt.expected {
+				t.Errorf(failed_msg, "TestNamespace "+fixture, tt.expected, tt.n)
+			}
+		}
+	}
+
+	// messages decoded without namespace are written in the canonical one
+	feed := BetRadarLiveOdds{Status: "alive", Timestamp: 1}
+	output, err := xml.Marshal(feed)
+	check(err)
+	expected := `<BetradarLiveOdds status="alive" timestamp="1" xmlns="` + Namespace + `"></BetradarLiveOdds>`
+	if string(output) != expected {
+		t.Errorf(failed_msg, "TestNamespace", expected, string(output))
+	}
+}
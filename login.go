@@ -0,0 +1,76 @@
+// Copyright 2013 Oscar Campos <oscar.campos@member.fsf.org>
+// See LICENSE file for details.
+
+package liveodds
+
+import (
+	"errors"
+	"strings"
+)
+
+// ReplyError is the BookmakerStatus type that BetRadar uses to reject a
+// login or to tell the bookmaker that something went wrong
+const ReplyError = "error"
+
+var (
+	// ErrLoginFailed is the reason of any login rejected by BetRadar
+	ErrLoginFailed = errors.New("liveodds: login failed")
+	// ErrRequestFailed is the reason of any other error reply, like a
+	// request for a match that does not exist
+	ErrRequestFailed = errors.New("liveodds: request failed")
+)
+
+// LoginError is a login rejected by BetRadar, Err is ErrLoginFailed so it
+// can be checked with errors.Is. The error reply does not carry a code, so
+// why it was rejected, like an unknown bookmaker or an IP address that is
+// not allowed, is only told by Text.
+type LoginError struct {
+	Type string // type of the BookmakerStatus reply
+	Text string // text of the reply, if any
+	Err  error
+}
+
+func (e *LoginError) Error() string {
+	if e.Text == "" {
+		return e.Err.Error()
+	}
+	return e.Err.Error() + ": " + e.Text
+}
+
+func (e *LoginError) Unwrap() error {
+	return e.Err
+}
+
+// RequestError is an error reply of BetRadar that is not the answer to a
+// login, the session goes on after it
+type RequestError struct {
+	ReplyNr uint32 // replynr of the request, 0 when the reply has none
+	Text    string // text of the reply, if any
+}
+
+func (e *RequestError) Error() string {
+	if e.Text == "" {
+		return ErrRequestFailed.Error()
+	}
+	return ErrRequestFailed.Error() + ": " + e.Text
+}
+
+func (e *RequestError) Unwrap() error {
+	return ErrRequestFailed
+}
+
+// Err returns the RequestError reported by an error reply, nil for any
+// other BookmakerStatus. The text of the reply is kept as it is, it is not
+// parsed to tell one error from another.
+func (s *BookMakerStatus) Err() error {
+	if s.Type != ReplyError {
+		return nil
+	}
+	return &RequestError{ReplyNr: s.ReplyNr, Text: strings.TrimSpace(s.Text)}
+}
+
+// newLoginError builds the LoginError for a reply that is not a successful
+// login
+func newLoginError(s *BookMakerStatus) *LoginError {
+	return &LoginError{Type: s.Type, Text: strings.TrimSpace(s.Text), Err: ErrLoginFailed}
+}
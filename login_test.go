@@ -0,0 +1,81 @@
+package liveodds
+
+import (
+	"encoding/xml"
+	"errors"
+	"testing"
+)
+
+func TestNewLoginError(t *testing.T) {
+	// the text is kept but it does not tell which error it is
+	for _, text := range []string{"Invalid bookmaker id 1234", "IP 10.0.0.1 is not allowed", "Something went wrong", ""} {
+		err := newLoginError(&BookMakerStatus{Type: ReplyError, Text: " " + text + " "})
+
+		var xmlTests = []xmlTest{
+			{err.Err, ErrLoginFailed},
+			{err.Text, text},
+			{err.Type, ReplyError},
+			{errors.Is(err, ErrLoginFailed), true},
+			{errors.Is(err, ErrRequestFailed), false},
+		}
+
+		for _, tt := range xmlTests {
+			if tt.n != tt.expected {
+				t.Errorf(failed_msg, "TestNewLoginError", tt.expected, tt.n)
+			}
+		}
+	}
+
+	if err := newLoginError(&BookMakerStatus{Type: ReplyError}); err.Error() != "liveodds: login failed" {
+		t.Errorf(failed_msg, "TestNewLoginError", "liveodds: login failed", err.Error())
+	}
+}
+
+func TestBookMakerStatusErr(t *testing.T) {
+	msg := `<BookmakerStatus timestamp="0" type="error" bookmakerid="1234">
+    IP 10.0.0.1 is not allowed
+</BookmakerStatus>`
+
+	status := BookMakerStatus{}
+	check(xml.Unmarshal([]byte(msg), &status))
+	err := status.Err()
+
+	var loginErr *LoginError
+	var xmlTests = []xmlTest{
+		{errors.Is(err, ErrRequestFailed), true},
+		{errors.As(err, &loginErr), false},
+		{err.Error(), "liveodds: request failed: IP 10.0.0.1 is not allowed"},
+		{(&BookMakerStatus{Type: ReplyError, Text: "Login failed"}).Err().Error(), "liveodds: request failed: Login failed"},
+		{(&BookMakerStatus{Type: RequestLogin}).Err(), nil},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestBookMakerStatusErr", tt.expected, tt.n)
+		}
+	}
+}
+
+func TestBookMakerStatusRequestErr(t *testing.T) {
+	status := BookMakerStatus{Type: ReplyError, ReplyNr: 3, Text: " Match 99 not found "}
+	err := status.Err()
+
+	var reqErr *RequestError
+	var xmlTests = []xmlTest{
+		{errors.Is(err, ErrRequestFailed), true},
+		{errors.Is(err, ErrLoginFailed), false},
+		{errors.As(err, &reqErr), true},
+		{err.Error(), "liveodds: request failed: Match 99 not found"},
+		{(&BookMakerStatus{Type: ReplyError}).Err().Error(), "liveodds: request failed"},
+		{errors.Is((&BookMakerStatus{Type: ReplyError, Text: "Session expired"}).Err(), ErrRequestFailed), true},
+	}
+	if reqErr != nil {
+		xmlTests = append(xmlTests, xmlTest{reqErr.ReplyNr, uint32(3)})
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestBookMakerStatusRequestErr", tt.expected, tt.n)
+		}
+	}
+}
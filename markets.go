@@ -0,0 +1,176 @@
+// Copyright 2013 Oscar Campos <oscar.campos@member.fsf.org>
+// See LICENSE file for details.
+
+package liveodds
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownMarket is returned when an odds typeid is not in the catalogue
+var ErrUnknownMarket = errors.New("liveodds: unknown market")
+
+// ErrMarketMismatch is returned when the type of an odds is not the type of
+// the market of its typeid
+var ErrMarketMismatch = errors.New("liveodds: odds type does not match its market")
+
+// ErrInvalidOutcome is returned when an OddsField type is not one of the
+// outcomes of its market
+var ErrInvalidOutcome = errors.New("liveodds: invalid outcome")
+
+// ErrInvalidSpecialValue is returned when the SpecialOddsValue of an odds
+// does not have the format that its market expects
+var ErrInvalidSpecialValue = errors.New("liveodds: invalid special odds value")
+
+// SpecialKind is the meaning of the SpecialOddsValue of a market
+type SpecialKind uint8
+
+const (
+	// SpecialNone means that the market does not use the special value
+	SpecialNone SpecialKind = iota
+	// SpecialLine is a total line like "2.5"
+	SpecialLine
+	// SpecialScore is the score when the odds were offered like "0:0",
+	// the market only counts what happens from that score on
+	SpecialScore
+	// SpecialHandicap is the goals given to each team like "0:1"
+	SpecialHandicap
+	// SpecialAsianHandicap is an asian handicap in quarters of a goal like
+	// "-1.5", "0.25" or "0:0.25"
+	SpecialAsianHandicap
+)
+
+func (k SpecialKind) String() string {
+	switch k {
+	case SpecialNone:
+		return "none"
+	case SpecialLine:
+		return "line"
+	case SpecialScore:
+		return "score"
+	case SpecialHandicap:
+		return "handicap"
+	case SpecialAsianHandicap:
+		return "asianhandicap"
+	}
+	return fmt.Sprintf("SpecialKind(%d)", k)
+}
+
+// Market describes an odds type of the feed. SubType 0 describes every
+// subtype of the TypeID that does not have its own Market.
+type Market struct {
+	TypeID   uint16
+	SubType  uint16
+	Type     string // the type attribute of the odds, like "ft3w"
+	Label    string
+	Outcomes []string // the OddsField types, like "1", "x" and "2"
+	Special  SpecialKind
+}
+
+var threeWay = []string{"1", "x", "2"}
+
+// DefaultMarkets are the markets that appear in the examples of the BetRadar
+// LiveOdds XML protocol documentation, kept in the fixtures directory: 3w and
+// hc are the odds types of the translation message, to, ft3w and ft2w the
+// odds of the change and clearbet messages. Bookmakers with other markets
+// enabled pass their own list to NewMarketCatalogue.
+var DefaultMarkets = []Market{
+	{TypeID: 2, Type: "3w", Label: "3way", Outcomes: threeWay},
+	{TypeID: 4, Type: "hc", Label: "Handicap", Outcomes: threeWay, Special: SpecialHandicap},
+	{TypeID: 5, Type: "to", Label: "Total", Outcomes: []string{"o", "u"}, Special: SpecialLine},
+	{TypeID: 6, Type: "ft3w", Label: "3way", Outcomes: threeWay, Special: SpecialScore},
+	{TypeID: 6, SubType: 4, Type: "ft3w", Label: "Who wins the rest of the match?", Outcomes: threeWay, Special: SpecialScore},
+	{TypeID: 6, SubType: 13, Type: "ft3w", Label: "Next goal", Outcomes: threeWay, Special: SpecialScore},
+	{TypeID: 6, SubType: 20, Type: "ft3w", Label: "Halftime - Who wins the rest?", Outcomes: threeWay, Special: SpecialScore},
+	{TypeID: 7, Type: "ft2w", Label: "2way", Outcomes: []string{"1", "2"}},
+	{TypeID: 7, SubType: 2, Type: "ft2w", Label: "Which team has kick off?", Outcomes: []string{"1", "2"}},
+}
+
+type marketType struct {
+	typeID  uint16
+	subType uint16
+}
+
+// MarketCatalogue finds the Market of the odds of the feed and validates
+// them against it. It is not modified after NewMarketCatalogue so it is
+// safe to use from several goroutines.
+type MarketCatalogue struct {
+	markets map[marketType]Market
+}
+
+// NewMarketCatalogue creates a catalogue with the given markets, or with
+// DefaultMarkets when none is given. A market replaces any previous one
+// with the same TypeID and SubType.
+func NewMarketCatalogue(markets ...Market) *MarketCatalogue {
+	if len(markets) == 0 {
+		markets = DefaultMarkets
+	}
+
+	c := &MarketCatalogue{markets: make(map[marketType]Market, len(markets))}
+	for _, market := range markets {
+		c.markets[marketType{market.TypeID, market.SubType}] = market
+	}
+	return c
+}
+
+// Lookup returns the Market of the given typeid and subtype, falling back to
+// the Market of the whole typeid
+func (c *MarketCatalogue) Lookup(typeID, subType uint16) (Market, bool) {
+	if market, ok := c.markets[marketType{typeID, subType}]; ok {
+		return market, true
+	}
+	market, ok := c.markets[marketType{typeID, 0}]
+	return market, ok
+}
+
+// Market returns the Market of odd
+func (c *MarketCatalogue) Market(odd *Odd) (Market, bool) {
+	return c.Lookup(odd.TypeID, odd.SubType)
+}
+
+// Validate checks that the type of odd is the one of its market, that its
+// OddsField types are outcomes of the market and that its SpecialOddsValue
+// has the expected format. Messages can carry only some of the outcomes so
+// missing outcomes are not an error.
+func (c *MarketCatalogue) Validate(odd *Odd) error {
+	market, ok := c.Market(odd)
+	if !ok {
+		return fmt.Errorf("%w typeid %d subtype %d", ErrUnknownMarket, odd.TypeID, odd.SubType)
+	}
+	if odd.Type != "" && market.Type != "" && odd.Type != market.Type {
+		return fmt.Errorf("%w %q in %s odds %d", ErrMarketMismatch, odd.Type, market.Type, odd.OddsID)
+	}
+
+	seen := make(map[string]bool, len(odd.OddsField))
+	for _, field := range odd.OddsField {
+		if !market.hasOutcome(field.Type) || seen[field.Type] {
+			return fmt.Errorf("%w %q in %s odds %d", ErrInvalidOutcome, field.Type, market.Type, odd.OddsID)
+		}
+		seen[field.Type] = true
+	}
+
+	if !market.validSpecial(odd.SpecialOddsValue) {
+		return fmt.Errorf("%w %q in %s odds %d", ErrInvalidSpecialValue, odd.SpecialOddsValue, market.Type, odd.OddsID)
+	}
+	return nil
+}
+
+func (m *Market) hasOutcome(outcome string) bool {
+	for _, o := range m.Outcomes {
+		if o == outcome {
+			return true
+		}
+	}
+	return false
+}
+
+// validSpecial reports whether s is a special value of the market, the
+// value can be missing as in clearbet and rollback messages
+func (m *Market) validSpecial(s string) bool {
+	if m.Special == SpecialNone {
+		return true
+	}
+	_, err := ParseSpecialValue(s, m.Special)
+	return err == nil
+}
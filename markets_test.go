@@ -0,0 +1,146 @@
+package liveodds
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMarketCatalogueLookup(t *testing.T) {
+	catalogue := NewMarketCatalogue()
+	change := LoadXMLFixture("fixtures/change.xml")
+
+	var labels []string
+	for i := range change.Matches[0].Odds {
+		odd := &change.Matches[0].Odds[i]
+		if err := catalogue.Validate(odd); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		market, _ := catalogue.Market(odd)
+		labels = append(labels, market.Label)
+	}
+
+	nextGoal, _ := catalogue.Lookup(6, 13)
+	other, otherOk := catalogue.Lookup(6, 99)
+	_, unknownOk := catalogue.Lookup(99, 0)
+
+	var xmlTests = []xmlTest{
+		{labels[0], "Next goal"},
+		{labels[1], "Total"},
+		{labels[2], "Halftime - Who wins the rest?"},
+		{labels[3], "Who wins the rest of the match?"},
+		{labels[4], "Which team has kick off?"},
+		{nextGoal.Special, SpecialScore},
+		{len(nextGoal.Outcomes), 3},
+		{otherOk, true},
+		{other.Label, "3way"},
+		{unknownOk, false},
+		{SpecialLine.String(), "line"},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestMarketCatalogueLookup", tt.expected, tt.n)
+		}
+	}
+}
+
+func TestMarketCatalogueValidate(t *testing.T) {
+	catalogue := NewMarketCatalogue()
+	total := func(special string, outcomes ...string) *Odd {
+		odd := &Odd{OddsID: 1, Type: "to", TypeID: 5, SpecialOddsValue: special}
+		for _, outcome := range outcomes {
+			odd.OddsField = append(odd.OddsField, OddsField{Type: outcome})
+		}
+		return odd
+	}
+
+	var tests = []struct {
+		odd      *Odd
+		expected error
+	}{
+		{total("2.5", "o", "u"), nil},
+		{total("2.5", "u"), nil},
+		{total(""), nil},
+		{total("2.5", "o", "x"), ErrInvalidOutcome},
+		{total("2.5", "o", "o"), ErrInvalidOutcome},
+		{total("0:0", "o", "u"), ErrInvalidSpecialValue},
+		{&Odd{TypeID: 6, SubType: 13, SpecialOddsValue: "1:0"}, nil},
+		{&Odd{TypeID: 6, SubType: 13, SpecialOddsValue: "2.5"}, ErrInvalidSpecialValue},
+		{&Odd{TypeID: 6, SubType: 13, SpecialOddsValue: "-:-"}, ErrInvalidSpecialValue},
+		{&Odd{TypeID: 7, SubType: 2, SpecialOddsValue: "-1"}, nil},
+		{&Odd{TypeID: 99}, ErrUnknownMarket},
+		{&Odd{TypeID: 6, Type: "ft3w", SpecialOddsValue: "0:0"}, nil},
+		{&Odd{TypeID: 6, Type: "to", SpecialOddsValue: "0:0"}, ErrMarketMismatch},
+	}
+
+	for _, tt := range tests {
+		err := catalogue.Validate(tt.odd)
+		if !errors.Is(err, tt.expected) || (tt.expected == nil && err != nil) {
+			t.Errorf(failed_msg, "TestMarketCatalogueValidate", tt.expected, err)
+		}
+	}
+}
+
+func TestMarketCatalogueCustom(t *testing.T) {
+	catalogue := NewMarketCatalogue(Market{TypeID: 5, Type: "to", Label: "Goals", Outcomes: []string{"o", "u", "e"}})
+	odd := &Odd{TypeID: 5, OddsField: []OddsField{{Type: "e"}}}
+
+	market, _ := catalogue.Market(odd)
+	_, ok := catalogue.Lookup(6, 0)
+	var xmlTests = []xmlTest{
+		{market.Label, "Goals"},
+		{catalogue.Validate(odd), nil},
+		{ok, false},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestMarketCatalogueCustom", tt.expected, tt.n)
+		}
+	}
+}
+
+func TestDefaultMarketsFixtures(t *testing.T) {
+	catalogue := NewMarketCatalogue()
+	fixtures := []string{
+		"fixtures/cancelbet.xml",
+		"fixtures/cancelbet_with_period.xml",
+		"fixtures/change.xml",
+		"fixtures/clearbet.xml",
+		"fixtures/rollback.xml",
+		"fixtures/undocancelbet.xml",
+	}
+
+	for _, fixture := range fixtures {
+		feed := LoadXMLFixture(fixture)
+		for _, m := range feed.Matches {
+			for i := range m.Odds {
+				if err := catalogue.Validate(&m.Odds[i]); err != nil {
+					t.Errorf(failed_msg, fixture, nil, err)
+				}
+			}
+		}
+	}
+
+	translation := LoadXMLFixture("fixtures/translation.xml")
+	for _, oddsType := range translation.OddsType {
+		market, ok := catalogue.Lookup(oddsType.TypeID, 0)
+		var xmlTests = []xmlTest{
+			{ok, true},
+			{market.Type, oddsType.Type},
+			{market.Label, oddsType.Name[0].Value},
+			{len(market.Outcomes), len(oddsType.OddsField)},
+		}
+		for i, field := range oddsType.OddsField {
+			if i < len(market.Outcomes) {
+				xmlTests = append(xmlTests, xmlTest{market.Outcomes[i], field.Type})
+			}
+		}
+
+		for _, tt := range xmlTests {
+			if tt.n != tt.expected {
+				t.Errorf(failed_msg, "TestDefaultMarketsFixtures", tt.expected, tt.n)
+			}
+		}
+	}
+}
@@ -0,0 +1,217 @@
+// Copyright 2013 Oscar Campos <oscar.campos@member.fsf.org>
+// See LICENSE file for details.
+
+package liveodds
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MatchState is the current state of a match as built by a MatchBook from
+// the messages of the feed
+type MatchState struct {
+	MatchID   uint32
+	Active    bool
+	Status    string
+	BetStatus string
+	Score     string
+	GameScore string
+	SetScores string
+	MatchTime uint16
+	MsgNR     uint32
+	Cards     []Card
+	Odds      map[uint32]Odd // keyed by OddsID
+	Updated   time.Time      // Epoch of the last message applied
+}
+
+// FieldChange is a match attribute that changed, values are formatted as
+// they are written in the feed
+type FieldChange struct {
+	Name string
+	Old  string
+	New  string
+}
+
+// MatchDiff is what a single message changed in a match
+type MatchDiff struct {
+	MatchID uint32
+	Created bool          // first message seen for this match
+	Fields  []FieldChange // match attributes that changed
+	Odds    []uint32      // IDs of the odds added or changed
+	Cards   []Card        // cards not seen before
+}
+
+// Empty reports whether the message did not change anything
+func (d *MatchDiff) Empty() bool {
+	return !d.Created && len(d.Fields) == 0 && len(d.Odds) == 0 && len(d.Cards) == 0
+}
+
+// MatchBook keeps the state of every match seen in the feed up to date
+// applying the change, score, betstart, betstop and alive messages to it.
+// It is safe to use from several goroutines.
+type MatchBook struct {
+	mu      sync.RWMutex
+	matches map[uint32]*MatchState
+}
+
+// NewMatchBook creates an empty MatchBook
+func NewMatchBook() *MatchBook {
+	return &MatchBook{matches: make(map[uint32]*MatchState)}
+}
+
+// Apply updates the state of the matches in msg and returns a diff for every
+// match that changed. Messages with any other status are ignored.
+func (b *MatchBook) Apply(msg *BetRadarLiveOdds) []MatchDiff {
+	switch msg.Kind() {
+	case StatusChange, StatusScore, StatusBetStart, StatusBetStop, StatusAlive:
+	default:
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var diffs []MatchDiff
+	for i := range msg.Matches {
+		m := &msg.Matches[i]
+		state, ok := b.matches[m.MatchID]
+		if !ok {
+			state = &MatchState{MatchID: m.MatchID, Odds: make(map[uint32]Odd)}
+			b.matches[m.MatchID] = state
+		}
+
+		diff := state.apply(m)
+		diff.Created = !ok
+		state.Updated = msg.Epoch()
+		if !diff.Empty() {
+			diffs = append(diffs, diff)
+		}
+	}
+	return diffs
+}
+
+// Snapshot returns a copy of the state of the given match, the copy is not
+// modified by later messages
+func (b *MatchBook) Snapshot(matchID uint32) (MatchState, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	state, ok := b.matches[matchID]
+	if !ok {
+		return MatchState{}, false
+	}
+	return state.copy(), true
+}
+
+// Matches returns the IDs of the matches in the book in ascending order
+func (b *MatchBook) Matches() []uint32 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	ids := make([]uint32, 0, len(b.matches))
+	for id := range b.matches {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// Remove forgets the given match
+func (b *MatchBook) Remove(matchID uint32) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.matches, matchID)
+}
+
+// apply merges m into the state. Attributes that are not present in the
+// message (zero values) keep their last known value.
+func (s *MatchState) apply(m *Match) MatchDiff {
+	diff := MatchDiff{MatchID: s.MatchID}
+	set := func(name string, field *string, value string) {
+		if value != "" && value != *field {
+			diff.Fields = append(diff.Fields, FieldChange{name, *field, value})
+			*field = value
+		}
+	}
+
+	if m.hasActive() && m.Active != s.Active {
+		diff.Fields = append(diff.Fields, FieldChange{"active",
+			fmt.Sprint(s.Active), fmt.Sprint(m.Active)})
+		s.Active = m.Active
+	}
+	set("status", &s.Status, m.Status)
+	set("betstatus", &s.BetStatus, m.BetStatus)
+	set("score", &s.Score, m.Score)
+	set("gamescore", &s.GameScore, m.GameScore)
+	set("setscores", &s.SetScores, m.SetScores)
+	if m.MatchTime != 0 && m.MatchTime != s.MatchTime {
+		diff.Fields = append(diff.Fields, FieldChange{"matchtime",
+			fmt.Sprint(s.MatchTime), fmt.Sprint(m.MatchTime)})
+		s.MatchTime = m.MatchTime
+	}
+	if m.MsgNR != 0 {
+		s.MsgNR = m.MsgNR
+	}
+
+	for _, card := range m.Card {
+		if !s.hasCard(card.CardID) {
+			s.Cards = append(s.Cards, card)
+			diff.Cards = append(diff.Cards, card)
+		}
+	}
+
+	for _, odd := range m.Odds {
+		old, ok := s.Odds[odd.OddsID]
+		merged := mergeOdd(old, odd)
+		if !ok || !reflect.DeepEqual(old, merged) {
+			s.Odds[odd.OddsID] = merged
+			diff.Odds = append(diff.Odds, odd.OddsID)
+		}
+	}
+	return diff
+}
+
+func (s *MatchState) hasCard(id uint32) bool {
+	for _, card := range s.Cards {
+		if card.CardID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeOdd returns update with the OddsField of old that are not in it, so
+// a message that only carries some outcomes does not lose the others
+func mergeOdd(old, update Odd) Odd {
+	merged := update
+	merged.OddsField = append([]OddsField(nil), update.OddsField...)
+	for _, field := range old.OddsField {
+		found := false
+		for _, f := range update.OddsField {
+			if f.Type == field.Type {
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged.OddsField = append(merged.OddsField, field)
+		}
+	}
+	return merged
+}
+
+// copy returns a deep copy of the state
+func (s *MatchState) copy() MatchState {
+	c := *s
+	c.Cards = append([]Card(nil), s.Cards...)
+	c.Odds = make(map[uint32]Odd, len(s.Odds))
+	for id, odd := range s.Odds {
+		odd.OddsField = append([]OddsField(nil), odd.OddsField...)
+		c.Odds[id] = odd
+	}
+	return c
+}
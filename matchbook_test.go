@@ -0,0 +1,175 @@
+package liveodds
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+func TestMatchBookScoreFlow(t *testing.T) {
+	book := NewMatchBook()
+
+	betstart := LoadXMLFixture("fixtures/betstart.xml")
+	score := LoadXMLFixture("fixtures/score.xml")
+	betstop := LoadXMLFixture("fixtures/betstop.xml")
+
+	created := book.Apply(&betstart)
+	scored := book.Apply(&score)
+	stopped := book.Apply(&betstop)
+	state, ok := book.Snapshot(935449)
+
+	var xmlTests = []xmlTest{
+		{len(created), 1},
+		{created[0].Created, true},
+		{len(scored), 1},
+		{scored[0].Created, false},
+		{scored[0].Fields[0], FieldChange{"status", "not_started", "1p"}},
+		{scored[0].Fields[1], FieldChange{"betstatus", "started", "stopped"}},
+		{scored[0].Fields[2], FieldChange{"score", ":", "0:1"}},
+		{scored[0].Fields[3], FieldChange{"setscores", "", "0:1"}},
+		{scored[0].Fields[4], FieldChange{"matchtime", "0", "3"}},
+		{len(stopped), 1},
+		{stopped[0].Fields[0], FieldChange{"status", "1p", "ended"}},
+		{stopped[0].Fields[1], FieldChange{"setscores", "0:1", "0:1 - 0:0"}},
+		{ok, true},
+		{state.Status, "ended"},
+		{state.BetStatus, "stopped"},
+		{state.Score, "0:1"},
+		{state.MatchTime, uint16(3)},
+		{state.MsgNR, uint32(51)},
+		{state.Updated, betstop.Epoch()},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestMatchBookScoreFlow", tt.expected, tt.n)
+		}
+	}
+}
+
+func TestMatchBookActive(t *testing.T) {
+	book := NewMatchBook()
+	betstart := LoadXMLFixture("fixtures/betstart.xml")
+	book.Apply(&betstart)
+
+	// a message that leaves active out keeps the match active
+	msg := `<BetradarLiveOdds status="score" timestamp="1386870302430">
+    <Match matchid="935449" msgnr="3" score="1:0"/>
+</BetradarLiveOdds>`
+	score := BetRadarLiveOdds{}
+	check(xml.Unmarshal([]byte(msg), &score))
+	scored := book.Apply(&score)
+	kept, _ := book.Snapshot(935449)
+
+	msg = `<BetradarLiveOdds status="change" timestamp="1386870302431">
+    <Match active="0" matchid="935449" msgnr="4"/>
+</BetradarLiveOdds>`
+	inactive := BetRadarLiveOdds{}
+	check(xml.Unmarshal([]byte(msg), &inactive))
+	deactivated := book.Apply(&inactive)
+	state, _ := book.Snapshot(935449)
+
+	var xmlTests = []xmlTest{
+		{len(scored[0].Fields), 1},
+		{scored[0].Fields[0], FieldChange{"score", ":", "1:0"}},
+		{kept.Active, true},
+		{deactivated[0].Fields[0], FieldChange{"active", "true", "false"}},
+		{state.Active, false},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestMatchBookActive", tt.expected, tt.n)
+		}
+	}
+}
+
+func TestMatchBookOdds(t *testing.T) {
+	book := NewMatchBook()
+	change := LoadXMLFixture("fixtures/change.xml")
+	first := book.Apply(&change)
+
+	// the same message again does not change anything
+	again := book.Apply(&change)
+
+	// a new price for a single outcome of the next goal market
+	update := LoadXMLFixture("fixtures/change.xml")
+	update.Matches[0].Odds = []Odd{update.Matches[0].Odds[0]}
+	update.Matches[0].Odds[0].OddsField = []OddsField{{Value: "4.5", Active: true, Type: "2"}}
+	updated := book.Apply(&update)
+
+	state, _ := book.Snapshot(867278)
+	odd := state.Odds[78557]
+
+	var xmlTests = []xmlTest{
+		{len(first), 1},
+		{len(first[0].Odds), 5},
+		{len(again), 0},
+		{len(updated), 1},
+		{len(updated[0].Odds), 1},
+		{updated[0].Odds[0], uint32(78557)},
+		{len(state.Odds), 5},
+		{len(odd.OddsField), 3},
+		{odd.OddsField[0].Type, "2"},
+		{odd.OddsField[0].Value, "4.5"},
+		{odd.OddsField[1].Value, "1.4"},
+		{odd.OddsField[2].Value, "7.0"},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestMatchBookOdds", tt.expected, tt.n)
+		}
+	}
+}
+
+func TestMatchBookCards(t *testing.T) {
+	book := NewMatchBook()
+	card := LoadXMLFixture("fixtures/card.xml")
+	first := book.Apply(&card)
+	again := book.Apply(&card)
+
+	var xmlTests = []xmlTest{
+		{len(first), 1},
+		{len(first[0].Cards), 2},
+		{first[0].Cards[0].Player, "Ramires"},
+		{len(again), 0},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestMatchBookCards", tt.expected, tt.n)
+		}
+	}
+}
+
+func TestMatchBookSnapshotIsACopy(t *testing.T) {
+	book := NewMatchBook()
+	change := LoadXMLFixture("fixtures/change.xml")
+	book.Apply(&change)
+
+	state, _ := book.Snapshot(867278)
+	state.Odds[78557].OddsField[0].Value = "100.0"
+	delete(state.Odds, 78558)
+	state.Score = "9:9"
+
+	fresh, _ := book.Snapshot(867278)
+	var xmlTests = []xmlTest{
+		{fresh.Odds[78557].OddsField[0].Value, "1.4"},
+		{len(fresh.Odds), 5},
+		{fresh.Score, "-:-"},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestMatchBookSnapshotIsACopy", tt.expected, tt.n)
+		}
+	}
+
+	if _, ok := book.Snapshot(1); ok {
+		t.Error("unexpected snapshot for an unknown match")
+	}
+	clearbet := LoadXMLFixture("fixtures/clearbet.xml")
+	if diffs := book.Apply(&clearbet); diffs != nil {
+		t.Errorf(failed_msg, "TestMatchBookSnapshotIsACopy", nil, diffs)
+	}
+}
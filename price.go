@@ -0,0 +1,126 @@
+// Copyright 2013 Oscar Campos <oscar.campos@member.fsf.org>
+// See LICENSE file for details.
+
+package liveodds
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PriceScale is the number of Price units in a decimal price of 1.0, so a
+// Price keeps up to four decimal digits without rounding
+const PriceScale = 10000
+
+// priceDigits is the number of decimal digits that fit in PriceScale
+const priceDigits = 4
+
+// ErrInvalidPrice is returned when a price is not an unsigned decimal
+// number with at most four decimal digits
+var ErrInvalidPrice = errors.New("liveodds: invalid price")
+
+// Price is a decimal (european) price in fixed point, 4.05 is stored as
+// 40500 so it is never affected by float rounding errors
+type Price int64
+
+// ParsePrice parses a decimal number as it comes in the OddsField values.
+// It does not reject values of 1.0 or lower, like the 0.5 of a total line
+// that is parsed the same way, OddsField.Price does.
+func ParsePrice(s string) (Price, error) {
+	s = strings.TrimSpace(s)
+	units, decimals := s, ""
+	dot := strings.IndexByte(s, '.')
+	if dot >= 0 {
+		units, decimals = s[:dot], s[dot+1:]
+	}
+	if units == "" || dot >= 0 && decimals == "" || len(decimals) > priceDigits ||
+		!isDigits(units) || !isDigits(decimals) {
+		return 0, fmt.Errorf("%w %q", ErrInvalidPrice, s)
+	}
+
+	n, err := strconv.ParseInt(units+decimals+strings.Repeat("0", priceDigits-len(decimals)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w %q", ErrInvalidPrice, s)
+	}
+	return Price(n), nil
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the decimal price with at least two decimal digits
+func (p Price) String() string {
+	s := fmt.Sprintf("%d.%04d", p/PriceScale, p%PriceScale)
+	for strings.HasSuffix(s, "0") && len(s)-strings.IndexByte(s, '.') > 3 {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+// Float64 returns the price as a float, only for display or statistics
+func (p Price) Float64() float64 {
+	return float64(p) / PriceScale
+}
+
+// Fractional returns the price in the fractional (british) format, 4.05 is
+// "61/20". Prices of 1.0 or lower do not have a fractional representation
+// and return an empty string.
+func (p Price) Fractional() string {
+	if p <= PriceScale {
+		return ""
+	}
+
+	num, den := int64(p-PriceScale), int64(PriceScale)
+	g := gcd(num, den)
+	return fmt.Sprintf("%d/%d", num/g, den/g)
+}
+
+// American returns the price in the american (moneyline) format, 4.05 is
+// "+305" and 1.45 is "-222". Prices of 1.0 or lower do not have an american
+// representation and return an empty string.
+func (p Price) American() string {
+	if p <= PriceScale {
+		return ""
+	}
+
+	profit := int64(p - PriceScale)
+	if p >= 2*PriceScale {
+		return fmt.Sprintf("+%d", roundDiv(profit*100, PriceScale))
+	}
+	return fmt.Sprintf("-%d", roundDiv(100*PriceScale, profit))
+}
+
+func gcd(a, b int64) int64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// roundDiv divides two positive numbers rounding half up
+func roundDiv(a, b int64) int64 {
+	return (2*a + b) / (2 * b)
+}
+
+// Price returns the price of the outcome, ok is false when the value is
+// empty (as in clearbet or rollback messages) or it is not a valid price,
+// which includes the prices of 1.0 or lower
+func (f *OddsField) Price() (price Price, ok bool) {
+	if f.Value == "" {
+		return 0, false
+	}
+
+	price, err := ParsePrice(f.Value)
+	if err != nil || price <= PriceScale {
+		return 0, false
+	}
+	return price, true
+}
@@ -0,0 +1,84 @@
+package liveodds
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParsePrice(t *testing.T) {
+	var xmlTests = []xmlTest{
+		{"4.05", Price(40500)},
+		{"1.4", Price(14000)},
+		{"7.0", Price(70000)},
+		{"1.001", Price(10010)},
+		{"12", Price(120000)},
+		{" 2.15 ", Price(21500)},
+		{"0", Price(0)},
+		{"0.5", Price(5000)},
+	}
+
+	for _, tt := range xmlTests {
+		price, err := ParsePrice(tt.n.(string))
+		if err != nil || price != tt.expected {
+			t.Errorf(failed_msg, "TestParsePrice", tt.expected, price)
+		}
+	}
+
+	for _, invalid := range []string{"", ".5", "1.", "1.00001", "-1.5", "1,5", "abc", "1e3"} {
+		if _, err := ParsePrice(invalid); !errors.Is(err, ErrInvalidPrice) {
+			t.Errorf(failed_msg, "TestParsePrice", ErrInvalidPrice, err)
+		}
+	}
+}
+
+func TestPriceFormats(t *testing.T) {
+	var xmlTests = []xmlTest{
+		{Price(40500).String(), "4.05"},
+		{Price(70000).String(), "7.00"},
+		{Price(10010).String(), "1.001"},
+		{Price(40500).Float64(), 4.05},
+		{Price(40500).Fractional(), "61/20"},
+		{Price(20000).Fractional(), "1/1"},
+		{Price(14500).Fractional(), "9/20"},
+		{Price(10000).Fractional(), ""},
+		{Price(40500).American(), "+305"},
+		{Price(20000).American(), "+100"},
+		{Price(14500).American(), "-222"},
+		{Price(18000).American(), "-125"},
+		{Price(10000).American(), ""},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestPriceFormats", tt.expected, tt.n)
+		}
+	}
+}
+
+func TestOddsFieldPrice(t *testing.T) {
+	change := LoadXMLFixture("fixtures/change.xml")
+	clearbet := LoadXMLFixture("fixtures/clearbet.xml")
+
+	away, ok := change.Matches[0].Odds[0].OddsField[2].Price()
+	_, cleared := clearbet.Matches[0].Odds[0].OddsField[0].Price()
+
+	var xmlTests = []xmlTest{
+		{away, Price(40500)},
+		{ok, true},
+		{cleared, false},
+	}
+	for _, value := range []string{"0", "1", "1.0", "0.5", "1.", "2."} {
+		field := OddsField{Value: value}
+		price, ok := field.Price()
+		xmlTests = append(xmlTests, xmlTest{price, Price(0)}, xmlTest{ok, false})
+	}
+	field := OddsField{Value: "1.01"}
+	price, ok := field.Price()
+	xmlTests = append(xmlTests, xmlTest{price, Price(10100)}, xmlTest{ok, true})
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestOddsFieldPrice", tt.expected, tt.n)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2013 Oscar Campos <oscar.campos@member.fsf.org>
+// See LICENSE file for details.
+
+package liveodds
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// SlowConsumerPolicy is what the Client does with the messages from the
+// feed when its buffer is full because they are not read fast enough
+type SlowConsumerPolicy uint8
+
+const (
+	// BlockOnFull stops reading from the connection until there is room
+	// in the buffer, the server may drop the connection if it takes long
+	BlockOnFull SlowConsumerPolicy = iota
+	// DropOldest discards the oldest message in the buffer
+	DropOldest
+	// CoalesceOdds merges a change message into the last one in the buffer
+	// when it is a change too, keeping the last update of every odds by
+	// OddsID. Once the buffer is full it blocks like BlockOnFull, but only
+	// after taking the message that can not be merged.
+	CoalesceOdds
+)
+
+func (p SlowConsumerPolicy) String() string {
+	switch p {
+	case BlockOnFull:
+		return "block"
+	case DropOldest:
+		return "dropoldest"
+	case CoalesceOdds:
+		return "coalesce"
+	}
+	return fmt.Sprintf("SlowConsumerPolicy(%d)", p)
+}
+
+// messageQueue buffers the messages between the goroutine that reads the
+// connection and the consumer applying a SlowConsumerPolicy when the
+// consumer falls behind
+type messageQueue struct {
+	in      chan Message
+	out     chan Message
+	quit    chan struct{} // the pending messages are not wanted anymore
+	size    int
+	policy  SlowConsumerPolicy
+	dropped uint64 // atomic
+}
+
+func newMessageQueue(size int, policy SlowConsumerPolicy) *messageQueue {
+	q := &messageQueue{
+		in:     make(chan Message),
+		out:    make(chan Message),
+		quit:   make(chan struct{}),
+		size:   size,
+		policy: policy,
+	}
+	go q.run()
+	return q
+}
+
+// run moves the messages from in to out until in is closed and every
+// pending message is delivered, or until quit is closed
+func (q *messageQueue) run() {
+	defer close(q.out)
+
+	var pending []Message
+	in := q.in
+	for in != nil || len(pending) > 0 {
+		var out chan Message
+		var next Message
+		if len(pending) > 0 {
+			out, next = q.out, pending[0]
+		}
+		// a full buffer does not take more messages until there is room,
+		// so the sender blocks
+		accept := in
+		if len(pending) >= q.size && !q.accepts(pending) {
+			accept = nil
+		}
+
+		select {
+		case msg, ok := <-accept:
+			if !ok {
+				in = nil
+				continue
+			}
+			pending = q.push(pending, msg)
+		case out <- next:
+			pending[0] = nil
+			pending = pending[1:]
+		case <-q.quit:
+			return
+		}
+	}
+}
+
+// accepts reports whether a full buffer can still take a message
+func (q *messageQueue) accepts(pending []Message) bool {
+	switch q.policy {
+	case DropOldest:
+		return true
+	case CoalesceOdds:
+		last, ok := pending[len(pending)-1].(*BetRadarLiveOdds)
+		return ok && last.Kind() == StatusChange && last.ReplyType == ""
+	}
+	return false
+}
+
+func (q *messageQueue) push(pending []Message, msg Message) []Message {
+	if q.policy == CoalesceOdds && len(pending) > 0 {
+		last, ok := pending[len(pending)-1].(*BetRadarLiveOdds)
+		if update, isOdds := msg.(*BetRadarLiveOdds); ok && isOdds && coalesceChange(last, update) {
+			return pending
+		}
+	}
+	if q.policy == DropOldest && len(pending) >= q.size {
+		atomic.AddUint64(&q.dropped, 1)
+		pending[0] = nil
+		pending = pending[1:]
+	}
+	return append(pending, msg)
+}
+
+// coalesceChange merges the change message update into the change message
+// dst, the odds of update replace the ones with the same OddsID in dst. It
+// reports false, doing nothing, when they are not both change messages.
+func coalesceChange(dst, update *BetRadarLiveOdds) bool {
+	if dst.Kind() != StatusChange || update.Kind() != StatusChange || dst.ReplyType != "" || update.ReplyType != "" {
+		return false
+	}
+
+	dst.Timestamp = update.Timestamp
+	for _, m := range update.Matches {
+		i := matchIndex(dst.Matches, m.MatchID)
+		if i < 0 {
+			dst.Matches = append(dst.Matches, m)
+			continue
+		}
+
+		merged := m
+		merged.Odds = append([]Odd(nil), dst.Matches[i].Odds...)
+		for _, odd := range m.Odds {
+			if j := oddIndex(merged.Odds, odd.OddsID); j >= 0 {
+				merged.Odds[j] = mergeOdd(merged.Odds[j], odd)
+			} else {
+				merged.Odds = append(merged.Odds, odd)
+			}
+		}
+		merged.Card = append(append([]Card(nil), dst.Matches[i].Card...), m.Card...)
+		merged.Scores = append(append([]Score(nil), dst.Matches[i].Scores...), m.Scores...)
+		dst.Matches[i] = merged
+	}
+	return true
+}
+
+func matchIndex(matches []Match, matchID uint32) int {
+	for i := range matches {
+		if matches[i].MatchID == matchID {
+			return i
+		}
+	}
+	return -1
+}
+
+func oddIndex(odds []Odd, oddsID uint32) int {
+	for i := range odds {
+		if odds[i].OddsID == oddsID {
+			return i
+		}
+	}
+	return -1
+}
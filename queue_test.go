@@ -0,0 +1,143 @@
+package liveodds
+
+import (
+	"testing"
+	"time"
+)
+
+func changeMessage(matchID, oddsID uint32, price string) *BetRadarLiveOdds {
+	return &BetRadarLiveOdds{
+		Status: "change",
+		Matches: []Match{{
+			MatchID: matchID,
+			Odds:    []Odd{{OddsID: oddsID, Active: true, OddsField: []OddsField{{Type: "1", Value: price}}}},
+		}},
+	}
+}
+
+// sent reports whether msg is accepted by q before a short timeout
+func sent(q *messageQueue, msg Message) bool {
+	select {
+	case q.in <- msg:
+		return true
+	case <-time.After(50 * time.Millisecond):
+		return false
+	}
+}
+
+func TestMessageQueueBlock(t *testing.T) {
+	q := newMessageQueue(2, BlockOnFull)
+	defer close(q.quit)
+
+	first, second, third := changeMessage(1, 1, "1.5"), changeMessage(1, 1, "1.6"), changeMessage(1, 1, "1.7")
+	var xmlTests = []xmlTest{
+		{sent(q, first), true},
+		{sent(q, second), true},
+		{sent(q, third), false},
+		{<-q.out, Message(first)},
+		{sent(q, third), true},
+		{<-q.out, Message(second)},
+		{<-q.out, Message(third)},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestMessageQueueBlock", tt.expected, tt.n)
+		}
+	}
+}
+
+func TestMessageQueueDropOldest(t *testing.T) {
+	q := newMessageQueue(2, DropOldest)
+	var msgs []*BetRadarLiveOdds
+	for i := 0; i < 5; i++ {
+		msg := changeMessage(1, uint32(i), "1.5")
+		msgs = append(msgs, msg)
+		if !sent(q, msg) {
+			t.Fatalf(failed_msg, "TestMessageQueueDropOldest", true, false)
+		}
+	}
+	close(q.in)
+
+	var received []Message
+	for msg := range q.out {
+		received = append(received, msg)
+	}
+
+	var xmlTests = []xmlTest{
+		{len(received), 2},
+		{received[0], Message(msgs[3])},
+		{received[1], Message(msgs[4])},
+		{q.dropped, uint64(3)},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestMessageQueueDropOldest", tt.expected, tt.n)
+		}
+	}
+}
+
+func TestMessageQueueCoalesce(t *testing.T) {
+	q := newMessageQueue(1, CoalesceOdds)
+	defer close(q.quit)
+
+	betstop := &BetRadarLiveOdds{Status: "betstop", Matches: []Match{{MatchID: 1}}}
+	last := changeMessage(1, 1, "1.7")
+	var xmlTests = []xmlTest{
+		{sent(q, changeMessage(1, 1, "1.5")), true},
+		{sent(q, changeMessage(1, 1, "1.6")), true},
+		{sent(q, changeMessage(1, 2, "2.5")), true},
+		{sent(q, changeMessage(2, 1, "3.5")), true},
+		// a full buffer takes the message that can not be merged and then
+		// blocks
+		{sent(q, betstop), true},
+		{sent(q, last), false},
+	}
+
+	merged := (<-q.out).(*BetRadarLiveOdds)
+	xmlTests = append(xmlTests,
+		xmlTest{len(merged.Matches), 2},
+		xmlTest{len(merged.Matches[0].Odds), 2},
+		xmlTest{merged.Matches[0].Odds[0].OddsField[0].Value, "1.6"},
+		xmlTest{merged.Matches[0].Odds[1].OddsField[0].Value, "2.5"},
+		xmlTest{merged.Matches[1].Odds[0].OddsField[0].Value, "3.5"},
+		xmlTest{sent(q, last), false},
+		xmlTest{<-q.out, Message(betstop)},
+		xmlTest{sent(q, last), true},
+		xmlTest{<-q.out, Message(last)},
+	)
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestMessageQueueCoalesce", tt.expected, tt.n)
+		}
+	}
+}
+
+func TestCoalesceChange(t *testing.T) {
+	dst := LoadXMLFixture("fixtures/change.xml")
+	update := LoadXMLFixture("fixtures/change.xml")
+	update.Timestamp++
+	update.Matches[0].MsgNR++
+	update.Matches[0].Odds = update.Matches[0].Odds[:1]
+	update.Matches[0].Odds[0].OddsField = []OddsField{{Type: "x", Value: "8.0", Active: true}}
+
+	betstop := LoadXMLFixture("fixtures/betstop.xml")
+	var xmlTests = []xmlTest{
+		{coalesceChange(&dst, &update), true},
+		{coalesceChange(&dst, &betstop), false},
+		{dst.Timestamp, update.Timestamp},
+		{dst.Matches[0].MsgNR, update.Matches[0].MsgNR},
+		{len(dst.Matches[0].Odds), 5},
+		{len(dst.Matches[0].Odds[0].OddsField), 3},
+		{dst.Matches[0].Odds[0].OddsField[0].Value, "8.0"},
+		{dst.Matches[0].Odds[0].OddsField[1].Value, "1.4"},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestCoalesceChange", tt.expected, tt.n)
+		}
+	}
+}
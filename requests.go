@@ -0,0 +1,92 @@
+// Copyright 2013 Oscar Campos <oscar.campos@member.fsf.org>
+// See LICENSE file for details.
+
+package liveodds
+
+import (
+	"math"
+	"time"
+)
+
+// Values of the BookmakerStatus type attribute for every request that a
+// bookmaker can send to BetRadar
+const (
+	RequestLogin               = "login"
+	RequestRegister            = "register"
+	RequestUnregister          = "unregister"
+	RequestMatchList           = "matchlist"
+	RequestCurrentOdds         = "currentodds"
+	RequestScoreAndCardSummary = "scoreandcardsummary"
+)
+
+// LoginRequest builds the login message, it must be the first message sent
+// after connecting to the feed
+func LoginRequest(bookmakerID uint16, key string) *BookMakerStatus {
+	return newRequest(&BookMakerStatus{Type: RequestLogin, BookmakerID: bookmakerID, Key: key})
+}
+
+// RegisterRequest builds the request that subscribes to the given matches
+func RegisterRequest(bookmakerID uint16, matchIDs ...uint32) *BookMakerStatus {
+	return matchesRequest(RequestRegister, bookmakerID, matchIDs)
+}
+
+// UnregisterRequest builds the request that cancels the subscription to the
+// given matches
+func UnregisterRequest(bookmakerID uint16, matchIDs ...uint32) *BookMakerStatus {
+	return matchesRequest(RequestUnregister, bookmakerID, matchIDs)
+}
+
+// MatchListRequest builds the request for the list of matches that start
+// from back before now to forward after now. BetRadar works with hours so
+// both durations are rounded up to the next hour, and they are capped to
+// the 65535 hours that fit in the request.
+func MatchListRequest(bookmakerID uint16, back, forward time.Duration) *BookMakerStatus {
+	return newRequest(&BookMakerStatus{
+		Type:         RequestMatchList,
+		BookmakerID:  bookmakerID,
+		HoursBack:    hours(back),
+		HoursForward: hours(forward),
+	})
+}
+
+// CurrentOddsRequest builds the request for the current odds of the given
+// matches, BetRadar replies sending all of them as if they had changed
+func CurrentOddsRequest(bookmakerID uint16, matchIDs ...uint32) *BookMakerStatus {
+	return matchesRequest(RequestCurrentOdds, bookmakerID, matchIDs)
+}
+
+// ScoreAndCardSummaryRequest builds the request for the scores and cards of
+// the given matches, the reply is a score message with the replytype
+// scoreandcardsummary
+func ScoreAndCardSummaryRequest(bookmakerID uint16, matchIDs ...uint32) *BookMakerStatus {
+	return matchesRequest(RequestScoreAndCardSummary, bookmakerID, matchIDs)
+}
+
+func matchesRequest(kind string, bookmakerID uint16, matchIDs []uint32) *BookMakerStatus {
+	v := &BookMakerStatus{Type: kind, BookmakerID: bookmakerID}
+	for _, id := range matchIDs {
+		v.Match = append(v.Match, Match{MatchID: id})
+	}
+	return newRequest(v)
+}
+
+// newRequest sets the timestamp of the request to the current time
+func newRequest(v *BookMakerStatus) *BookMakerStatus {
+	v.SetEpoch(time.Now())
+	return v
+}
+
+// hours rounds d up to the next hour, capped to math.MaxUint16
+func hours(d time.Duration) uint16 {
+	if d <= 0 {
+		return 0
+	}
+	n := d / time.Hour
+	if d%time.Hour != 0 {
+		n++
+	}
+	if n > math.MaxUint16 {
+		return math.MaxUint16
+	}
+	return uint16(n)
+}
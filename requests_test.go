@@ -0,0 +1,102 @@
+package liveodds
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+	"math"
+	"testing"
+	"time"
+)
+
+type requestTest struct {
+	request *BookMakerStatus
+	golden  string
+}
+
+var requestTests = []requestTest{
+	{LoginRequest(1234, "secret"), "fixtures/login_request.xml"},
+	{RegisterRequest(1234, 935457, 867278), "fixtures/register_request.xml"},
+	{UnregisterRequest(1234, 935457), "fixtures/unregister_request.xml"},
+	{MatchListRequest(1234, 2*time.Hour, 90*time.Minute), "fixtures/matchlist_request.xml"},
+	{CurrentOddsRequest(1234, 867278), "fixtures/currentodds_request.xml"},
+	{ScoreAndCardSummaryRequest(1234, 1355389), "fixtures/scoreandcardsummary_request.xml"},
+}
+
+func TestRequestsGolden(t *testing.T) {
+	for _, tt := range requestTests {
+		// the golden files have a zero timestamp
+		request := *tt.request
+		request.Timestamp = 0
+		output, err := xml.MarshalIndent(&request, "", "    ")
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+			continue
+		}
+
+		golden, err := ioutil.ReadFile(tt.golden)
+		check(err)
+		if !bytes.Equal(output, bytes.TrimSpace(golden)) {
+			t.Errorf(failed_msg, tt.golden, string(golden), string(output))
+		}
+	}
+}
+
+func TestRequestsRoundTrip(t *testing.T) {
+	for _, tt := range requestTests {
+		output, err := xml.Marshal(tt.request)
+		check(err)
+
+		msg, err := NewDecoder(bytes.NewReader(output)).Decode()
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+			continue
+		}
+		feed := msg.(*BookMakerStatus)
+
+		var xmlTests = []xmlTest{
+			{feed.Timestamp, tt.request.Timestamp},
+			{feed.Type, tt.request.Type},
+			{feed.BookmakerID, tt.request.BookmakerID},
+			{feed.Key, tt.request.Key},
+			{feed.HoursBack, tt.request.HoursBack},
+			{feed.HoursForward, tt.request.HoursForward},
+			{len(feed.Match), len(tt.request.Match)},
+		}
+
+		for _, tt := range xmlTests {
+			if tt.n != tt.expected {
+				t.Errorf(failed_msg, "TestRequestsRoundTrip", tt.expected, tt.n)
+			}
+		}
+	}
+}
+
+func TestRequestsTimestamp(t *testing.T) {
+	before := time.Now().Truncate(time.Millisecond)
+	request := CurrentOddsRequest(1234, 867278)
+	after := time.Now()
+
+	if epoch := request.Epoch(); epoch.Before(before) || epoch.After(after) {
+		t.Errorf(failed_msg, "TestRequestsTimestamp", before, epoch)
+	}
+}
+
+func TestRequestsHours(t *testing.T) {
+	var xmlTests = []xmlTest{
+		{hours(-time.Hour), uint16(0)},
+		{hours(0), uint16(0)},
+		{hours(time.Minute), uint16(1)},
+		{hours(2 * time.Hour), uint16(2)},
+		{hours(65535 * time.Hour), uint16(65535)},
+		{hours(65536 * time.Hour), uint16(65535)},
+		{hours(math.MaxInt64), uint16(65535)},
+		{MatchListRequest(1234, 100000*time.Hour, 0).HoursBack, uint16(65535)},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestRequestsHours", tt.expected, tt.n)
+		}
+	}
+}
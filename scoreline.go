@@ -0,0 +1,108 @@
+// Copyright 2013 Oscar Campos <oscar.campos@member.fsf.org>
+// See LICENSE file for details.
+
+package liveodds
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidScore is returned when a score is not in the "home:away" format
+var ErrInvalidScore = errors.New("liveodds: invalid score")
+
+// ScoreLine is a score in the "home:away" format used all over the feed.
+// BetRadar uses "-:-" and ":" when the score is not known yet (the match
+// did not start for example), those are parsed as a ScoreLine with Known
+// set to false.
+type ScoreLine struct {
+	Home  int
+	Away  int
+	Known bool
+}
+
+// ParseScoreLine parses a "home:away" score, an empty string is parsed as
+// an unknown score
+func ParseScoreLine(s string) (ScoreLine, error) {
+	s = strings.TrimSpace(s)
+	switch s {
+	case "", ":", "-:-":
+		return ScoreLine{}, nil
+	}
+
+	i := strings.IndexByte(s, ':')
+	if i < 0 {
+		return ScoreLine{}, fmt.Errorf("%w %q", ErrInvalidScore, s)
+	}
+	home, err := strconv.ParseUint(s[:i], 10, 16)
+	if err != nil {
+		return ScoreLine{}, fmt.Errorf("%w %q", ErrInvalidScore, s)
+	}
+	away, err := strconv.ParseUint(s[i+1:], 10, 16)
+	if err != nil {
+		return ScoreLine{}, fmt.Errorf("%w %q", ErrInvalidScore, s)
+	}
+	return ScoreLine{Home: int(home), Away: int(away), Known: true}, nil
+}
+
+// ParseSetScores parses a list of scores like "0:1 - 0:0" as they come in
+// the setscores attribute
+func ParseSetScores(s string) ([]ScoreLine, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	var scores []ScoreLine
+	for _, part := range strings.Split(s, " - ") {
+		score, err := ParseScoreLine(part)
+		if err != nil {
+			return nil, err
+		}
+		scores = append(scores, score)
+	}
+	return scores, nil
+}
+
+// String returns the score in the feed format, "-:-" when it is unknown
+func (s ScoreLine) String() string {
+	if !s.Known {
+		return "-:-"
+	}
+	return fmt.Sprintf("%d:%d", s.Home, s.Away)
+}
+
+// ScoreLine returns the parsed Score of the match
+func (m *Match) ScoreLine() (ScoreLine, error) {
+	return ParseScoreLine(m.Score)
+}
+
+// GameScoreLine returns the parsed GameScore of the match
+func (m *Match) GameScoreLine() (ScoreLine, error) {
+	return ParseScoreLine(m.GameScore)
+}
+
+// ClearedScoreLine returns the parsed ClearedScore of the match
+func (m *Match) ClearedScoreLine() (ScoreLine, error) {
+	return ParseScoreLine(m.ClearedScore)
+}
+
+// SetScoreLines returns the score of every period of the match. It uses the
+// setscores attribute when it is present and the tennis setscoreN attributes
+// otherwise.
+func (m *Match) SetScoreLines() ([]ScoreLine, error) {
+	if m.SetScores != "" || len(m.SetScore) == 0 {
+		return ParseSetScores(m.SetScores)
+	}
+
+	scores := make([]ScoreLine, len(m.SetScore))
+	for i, set := range m.SetScore {
+		score, err := ParseScoreLine(set)
+		if err != nil {
+			return nil, err
+		}
+		scores[i] = score
+	}
+	return scores, nil
+}
@@ -0,0 +1,89 @@
+package liveodds
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseScoreLine(t *testing.T) {
+	var xmlTests = []xmlTest{
+		{"1:0", ScoreLine{1, 0, true}},
+		{"12:103", ScoreLine{12, 103, true}},
+		{"-:-", ScoreLine{}},
+		{":", ScoreLine{}},
+		{"", ScoreLine{}},
+	}
+
+	for _, tt := range xmlTests {
+		score, err := ParseScoreLine(tt.n.(string))
+		if err != nil || score != tt.expected {
+			t.Errorf(failed_msg, "TestParseScoreLine", tt.expected, score)
+		}
+	}
+
+	for _, invalid := range []string{"1-0", "a:b", "1:", ":1", "1:-", "-1:0", "1:0:0"} {
+		if _, err := ParseScoreLine(invalid); !errors.Is(err, ErrInvalidScore) {
+			t.Errorf(failed_msg, "TestParseScoreLine", ErrInvalidScore, err)
+		}
+	}
+}
+
+func TestScoreLineString(t *testing.T) {
+	var xmlTests = []xmlTest{
+		{ScoreLine{3, 0, true}.String(), "3:0"},
+		{ScoreLine{}.String(), "-:-"},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestScoreLineString", tt.expected, tt.n)
+		}
+	}
+}
+
+func TestMatchScoreLines(t *testing.T) {
+	betstop := LoadXMLFixture("fixtures/betstop.xml")
+	betstart := LoadXMLFixture("fixtures/betstart.xml")
+	clearbet := LoadXMLFixture("fixtures/clearbet.xml")
+	alive := LoadXMLFixture("fixtures/alive.xml")
+
+	score, err := betstop.Matches[0].ScoreLine()
+	check(err)
+	sets, err := betstop.Matches[0].SetScoreLines()
+	check(err)
+	notStarted, err := betstart.Matches[0].ScoreLine()
+	check(err)
+	cleared, err := clearbet.Matches[0].ClearedScoreLine()
+	check(err)
+	game, err := alive.Matches[0].GameScoreLine()
+	check(err)
+	tennis, err := alive.Matches[0].SetScoreLines()
+	check(err)
+
+	var xmlTests = []xmlTest{
+		{score, ScoreLine{0, 1, true}},
+		{len(sets), 2},
+		{sets[0], ScoreLine{0, 1, true}},
+		{sets[1], ScoreLine{0, 0, true}},
+		{notStarted.Known, false},
+		{cleared, ScoreLine{0, 0, true}},
+		{game, ScoreLine{0, 0, true}},
+		{len(tennis), 2},
+		{tennis[0], ScoreLine{6, 3, true}},
+		{tennis[1], ScoreLine{1, 3, true}},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestMatchScoreLines", tt.expected, tt.n)
+		}
+	}
+
+	malformed := Match{Score: "1-0", SetScores: "1:0 - x"}
+	if _, err := malformed.ScoreLine(); !errors.Is(err, ErrInvalidScore) {
+		t.Errorf(failed_msg, "TestMatchScoreLines", ErrInvalidScore, err)
+	}
+	if _, err := malformed.SetScoreLines(); !errors.Is(err, ErrInvalidScore) {
+		t.Errorf(failed_msg, "TestMatchScoreLines", ErrInvalidScore, err)
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2013 Oscar Campos <oscar.campos@member.fsf.org>
+// See LICENSE file for details.
+
+package liveodds
+
+import (
+	"fmt"
+	"sync"
+)
+
+// SequenceEventKind is the kind of anomaly found in the msgnr sequence of
+// a match
+type SequenceEventKind uint8
+
+const (
+	// SequenceGap means that one or more messages were missed
+	SequenceGap SequenceEventKind = iota + 1
+	// SequenceDuplicate means that a message number was already seen
+	SequenceDuplicate
+	// SequenceReset means that BetRadar started counting again, found by
+	// a msgnr far behind the last one, or back to the first few right
+	// after a reconnection
+	SequenceReset
+)
+
+const (
+	// resetMaxMsgNR is the highest msgnr taken as the start of a new
+	// sequence after a reconnection, so a reset is found even when its
+	// first messages are lost
+	resetMaxMsgNR = 3
+	// duplicateWindow is how far behind the last msgnr a message can be
+	// to be a duplicate, beyond it the counter was reset
+	duplicateWindow = 64
+)
+
+func (k SequenceEventKind) String() string {
+	switch k {
+	case SequenceGap:
+		return "gap"
+	case SequenceDuplicate:
+		return "duplicate"
+	case SequenceReset:
+		return "reset"
+	}
+	return fmt.Sprintf("SequenceEventKind(%d)", k)
+}
+
+// SequenceEvent reports an anomaly in the msgnr sequence of a match. When
+// an alive message reveals a gap Got is the last msgnr that BetRadar sent,
+// so the messages from Expected to Got (both included) were missed.
+type SequenceEvent struct {
+	Kind     SequenceEventKind
+	MatchID  uint32
+	Expected uint32 // the msgnr that should have come
+	Got      uint32 // the msgnr that came
+}
+
+// SequenceTracker follows the Match.MsgNR of every match and reports the
+// messages that are skipped or repeated. Replies to requests and messages
+// without msgnr are not part of the sequence and are ignored.
+// It is safe to use from several goroutines.
+type SequenceTracker struct {
+	mu          sync.Mutex
+	last        map[uint32]uint32
+	reconnected map[uint32]bool // no message seen since the last reconnection
+}
+
+// NewSequenceTracker creates a SequenceTracker with no matches
+func NewSequenceTracker() *SequenceTracker {
+	return &SequenceTracker{
+		last:        make(map[uint32]uint32),
+		reconnected: make(map[uint32]bool),
+	}
+}
+
+// Reconnected tells the tracker that a new session started, the next low
+// msgnr of every match is taken as a reset instead of as a duplicate
+func (t *SequenceTracker) Reconnected() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id := range t.last {
+		t.reconnected[id] = true
+	}
+}
+
+// Apply checks the msgnr of every match in msg against the last one seen
+// for that match and returns the anomalies found
+func (t *SequenceTracker) Apply(msg *BetRadarLiveOdds) []SequenceEvent {
+	if msg.ReplyType != "" {
+		return nil
+	}
+	alive := msg.Kind() == StatusAlive
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var events []SequenceEvent
+	for i := range msg.Matches {
+		m := &msg.Matches[i]
+		if m.MsgNR == 0 {
+			continue
+		}
+
+		last, ok := t.last[m.MatchID]
+		if !ok {
+			t.last[m.MatchID] = m.MsgNR
+			continue
+		}
+
+		if alive {
+			// alive messages carry the number of the last message sent
+			// for the match instead of a new one
+			if m.MsgNR > last {
+				events = append(events, SequenceEvent{SequenceGap, m.MatchID, last + 1, m.MsgNR})
+				t.last[m.MatchID] = m.MsgNR
+			}
+			continue
+		}
+
+		reconnected := t.reconnected[m.MatchID]
+		delete(t.reconnected, m.MatchID)
+
+		expected := last + 1
+		switch {
+		case m.MsgNR == expected:
+		case m.MsgNR < expected && (reconnected && m.MsgNR <= resetMaxMsgNR || last-m.MsgNR >= duplicateWindow):
+			events = append(events, SequenceEvent{SequenceReset, m.MatchID, expected, m.MsgNR})
+		case m.MsgNR > expected:
+			events = append(events, SequenceEvent{SequenceGap, m.MatchID, expected, m.MsgNR})
+		default:
+			events = append(events, SequenceEvent{SequenceDuplicate, m.MatchID, expected, m.MsgNR})
+			continue
+		}
+		t.last[m.MatchID] = m.MsgNR
+	}
+	return events
+}
+
+// Last returns the last msgnr seen for the given match
+func (t *SequenceTracker) Last(matchID uint32) (uint32, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	last, ok := t.last[matchID]
+	return last, ok
+}
+
+// Forget stops tracking the given match
+func (t *SequenceTracker) Forget(matchID uint32) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.last, matchID)
+	delete(t.reconnected, matchID)
+}
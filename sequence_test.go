@@ -0,0 +1,152 @@
+package liveodds
+
+import (
+	"testing"
+)
+
+// sequenceMessage builds a message with the given status for match 1
+func sequenceMessage(status string, msgnr uint32) *BetRadarLiveOdds {
+	return &BetRadarLiveOdds{
+		Status:  status,
+		Matches: []Match{{MatchID: 1, MsgNR: msgnr}},
+	}
+}
+
+func TestSequenceTracker(t *testing.T) {
+	tracker := NewSequenceTracker()
+	apply := func(status string, msgnr uint32) []SequenceEvent {
+		return tracker.Apply(sequenceMessage(status, msgnr))
+	}
+	reconnect := func() bool {
+		tracker.Reconnected()
+		return true
+	}
+
+	var xmlTests = []xmlTest{
+		{len(apply("change", 10)), 0},
+		{len(apply("change", 11)), 0},
+		{len(apply("alive", 11)), 0},
+		{apply("change", 14)[0], SequenceEvent{SequenceGap, 1, 12, 14}},
+		{apply("betstop", 14)[0], SequenceEvent{SequenceDuplicate, 1, 15, 14}},
+		{apply("change", 12)[0], SequenceEvent{SequenceDuplicate, 1, 15, 12}},
+		{len(apply("change", 15)), 0},
+		{apply("alive", 17)[0], SequenceEvent{SequenceGap, 1, 16, 17}},
+		{len(apply("change", 18)), 0},
+		{reconnect(), true},
+		{apply("change", 1)[0], SequenceEvent{SequenceReset, 1, 19, 1}},
+		{len(apply("change", 2)), 0},
+		{len(apply("change", 0)), 0},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestSequenceTracker", tt.expected, tt.n)
+		}
+	}
+
+	last, ok := tracker.Last(1)
+	if !ok || last != 2 {
+		t.Errorf(failed_msg, "TestSequenceTracker", 2, last)
+	}
+}
+
+func TestSequenceTrackerIgnoresReplies(t *testing.T) {
+	tracker := NewSequenceTracker()
+	tracker.Apply(sequenceMessage("change", 10))
+
+	reply := sequenceMessage("change", 1)
+	reply.ReplyType = "currentodds"
+	if events := tracker.Apply(reply); len(events) != 0 {
+		t.Errorf(failed_msg, "TestSequenceTrackerIgnoresReplies", 0, len(events))
+	}
+
+	tracker.Forget(1)
+	if _, ok := tracker.Last(1); ok {
+		t.Error("match 1 should not be tracked anymore")
+	}
+}
+
+func TestSequenceFixtures(t *testing.T) {
+	tracker := NewSequenceTracker()
+	clearbet := LoadXMLFixture("fixtures/clearbet.xml")
+	rollback := LoadXMLFixture("fixtures/rollback.xml")
+	tracker.Apply(&clearbet)
+
+	events := tracker.Apply(&rollback)
+	if len(events) != 1 || events[0] != (SequenceEvent{SequenceGap, 793862, 9, 10}) {
+		t.Errorf(failed_msg, "TestSequenceFixtures", "gap from 9 to 10", events)
+	}
+}
+
+func TestSequenceTrackerLongMatch(t *testing.T) {
+	tracker := NewSequenceTracker()
+	tracker.Apply(sequenceMessage("change", 65535))
+
+	if events := tracker.Apply(sequenceMessage("change", 65536)); len(events) != 0 {
+		t.Errorf(failed_msg, "TestSequenceTrackerLongMatch", 0, events)
+	}
+	if last, _ := tracker.Last(1); last != 65536 {
+		t.Errorf(failed_msg, "TestSequenceTrackerLongMatch", 65536, last)
+	}
+}
+
+func TestSequenceTrackerResetWithLostMessages(t *testing.T) {
+	tracker := NewSequenceTracker()
+	tracker.Apply(sequenceMessage("change", 500))
+
+	var xmlTests = []xmlTest{
+		// message 1 of the new sequence is lost
+		{tracker.Apply(sequenceMessage("change", 2))[0], SequenceEvent{SequenceReset, 1, 501, 2}},
+		{len(tracker.Apply(sequenceMessage("change", 3))), 0},
+		{len(tracker.Apply(sequenceMessage("change", 4))), 0},
+		{tracker.Apply(sequenceMessage("change", 6))[0], SequenceEvent{SequenceGap, 1, 5, 6}},
+		{tracker.Apply(sequenceMessage("change", 4))[0], SequenceEvent{SequenceDuplicate, 1, 7, 4}},
+	}
+	tracker.Apply(sequenceMessage("change", 300))
+	xmlTests = append(xmlTests,
+		// restarted far behind the last msgnr
+		xmlTest{tracker.Apply(sequenceMessage("change", 40))[0], SequenceEvent{SequenceReset, 1, 301, 40}},
+		xmlTest{len(tracker.Apply(sequenceMessage("change", 41))), 0},
+	)
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestSequenceTrackerResetWithLostMessages", tt.expected, tt.n)
+		}
+	}
+}
+
+func TestSequenceTrackerLateLowMsgNR(t *testing.T) {
+	tracker := NewSequenceTracker()
+	var events []SequenceEvent
+	for _, msgnr := range []uint32{1, 2, 3, 4, 2, 5} {
+		events = append(events, tracker.Apply(sequenceMessage("change", msgnr))...)
+	}
+
+	expected := SequenceEvent{SequenceDuplicate, 1, 5, 2}
+	if len(events) != 1 || events[0] != expected {
+		t.Errorf(failed_msg, "TestSequenceTrackerLateLowMsgNR", expected, events)
+	}
+	if last, _ := tracker.Last(1); last != 5 {
+		t.Errorf(failed_msg, "TestSequenceTrackerLateLowMsgNR", 5, last)
+	}
+}
+
+func TestSequenceTrackerResetAfterReconnect(t *testing.T) {
+	tracker := NewSequenceTracker()
+	tracker.Apply(sequenceMessage("change", 20))
+	tracker.Reconnected()
+
+	var xmlTests = []xmlTest{
+		{tracker.Apply(sequenceMessage("change", 2))[0], SequenceEvent{SequenceReset, 1, 21, 2}},
+		{len(tracker.Apply(sequenceMessage("change", 3))), 0},
+		// only the first message after the reconnection can be a reset
+		{tracker.Apply(sequenceMessage("change", 1))[0], SequenceEvent{SequenceDuplicate, 1, 4, 1}},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestSequenceTrackerResetAfterReconnect", tt.expected, tt.n)
+		}
+	}
+}
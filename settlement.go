@@ -0,0 +1,221 @@
+// Copyright 2013 Oscar Campos <oscar.campos@member.fsf.org>
+// See LICENSE file for details.
+
+package liveodds
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// BetResult is the settlement of a bet on a single outcome
+type BetResult uint8
+
+const (
+	// ResultPending means that the outcome has not been cleared yet
+	ResultPending BetResult = iota
+	ResultWon
+	ResultLost
+	// ResultVoid means that the bet was cancelled and has to be refunded
+	ResultVoid
+)
+
+func (r BetResult) String() string {
+	switch r {
+	case ResultPending:
+		return "pending"
+	case ResultWon:
+		return "won"
+	case ResultLost:
+		return "lost"
+	case ResultVoid:
+		return "void"
+	}
+	return fmt.Sprintf("BetResult(%d)", r)
+}
+
+// period is a cancelbet time range, zero bounds are open
+type period struct {
+	start, end int64 // BetRadar epoch milliseconds
+}
+
+func (p period) contains(ms int64) bool {
+	return (p.start == 0 || ms >= p.start) && (p.end == 0 || ms <= p.end)
+}
+
+type marketKey struct {
+	matchID uint32
+	oddsID  uint32
+}
+
+// market is the settlement of a single odds of a match
+type market struct {
+	outcomes map[string]bool // outcome type -> won
+	cancels  []period
+}
+
+// SettlementLedger records the results that BetRadar sends in clearbet
+// messages and the cancellations sent in cancelbet messages, undoing them
+// on rollback and undocancelbet, so it can tell the result of any bet:
+//
+//	ledger := NewSettlementLedger()
+//	ledger.Apply(msg) // for every message of the feed
+//	result := ledger.Result(matchID, oddsID, "1", placedAt)
+//
+// It is safe to use from several goroutines.
+type SettlementLedger struct {
+	mu      sync.RWMutex
+	markets map[marketKey]*market
+	matches map[uint32][]period // cancellations of whole matches
+}
+
+// NewSettlementLedger creates an empty SettlementLedger
+func NewSettlementLedger() *SettlementLedger {
+	return &SettlementLedger{
+		markets: make(map[marketKey]*market),
+		matches: make(map[uint32][]period),
+	}
+}
+
+// Apply records the settlement carried by a clearbet, rollback, cancelbet
+// or undocancelbet message, any other message is ignored
+func (l *SettlementLedger) Apply(msg *BetRadarLiveOdds) {
+	kind := msg.Kind()
+	switch kind {
+	case StatusClearBet, StatusRollback, StatusCancelBet, StatusUndoCancelBet:
+	default:
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	p := period{msg.StartTime, msg.EndTime}
+	for i := range msg.Matches {
+		m := &msg.Matches[i]
+		switch kind {
+		case StatusClearBet:
+			l.clear(m)
+		case StatusRollback:
+			l.rollback(m)
+		case StatusCancelBet:
+			l.cancel(m, p)
+		case StatusUndoCancelBet:
+			l.undoCancel(m, p)
+		}
+	}
+}
+
+func (l *SettlementLedger) market(matchID, oddsID uint32) *market {
+	key := marketKey{matchID, oddsID}
+	mk, ok := l.markets[key]
+	if !ok {
+		mk = &market{outcomes: make(map[string]bool)}
+		l.markets[key] = mk
+	}
+	return mk
+}
+
+func (l *SettlementLedger) clear(m *Match) {
+	for _, odd := range m.Odds {
+		mk := l.market(m.MatchID, odd.OddsID)
+		for _, field := range odd.OddsField {
+			// a field without the outcome attribute stays unresolved
+			if !field.hasOutcome() {
+				continue
+			}
+			mk.outcomes[field.Type] = field.Outcome
+		}
+	}
+}
+
+func (l *SettlementLedger) rollback(m *Match) {
+	for _, odd := range m.Odds {
+		mk, ok := l.markets[marketKey{m.MatchID, odd.OddsID}]
+		if !ok {
+			continue
+		}
+		if len(odd.OddsField) == 0 {
+			mk.outcomes = make(map[string]bool)
+			continue
+		}
+		for _, field := range odd.OddsField {
+			delete(mk.outcomes, field.Type)
+		}
+	}
+}
+
+// cancel voids the bets placed in p on the odds listed in m, or on every
+// odds of the match when none is listed
+func (l *SettlementLedger) cancel(m *Match, p period) {
+	if len(m.Odds) == 0 {
+		l.matches[m.MatchID] = append(l.matches[m.MatchID], p)
+		return
+	}
+	for _, odd := range m.Odds {
+		mk := l.market(m.MatchID, odd.OddsID)
+		mk.cancels = append(mk.cancels, p)
+	}
+}
+
+// undoCancel restores the bets voided by a previous cancel with the same
+// period, or by every previous cancel when the undo does not have a period
+func (l *SettlementLedger) undoCancel(m *Match, p period) {
+	if len(m.Odds) == 0 {
+		l.matches[m.MatchID] = removePeriod(l.matches[m.MatchID], p)
+		return
+	}
+	for _, odd := range m.Odds {
+		if mk, ok := l.markets[marketKey{m.MatchID, odd.OddsID}]; ok {
+			mk.cancels = removePeriod(mk.cancels, p)
+		}
+	}
+}
+
+func removePeriod(periods []period, p period) []period {
+	if p == (period{}) {
+		return nil
+	}
+
+	kept := periods[:0]
+	for _, c := range periods {
+		if c != p {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// Result returns the result of a bet on the given outcome type of an odds
+// placed at the given time
+func (l *SettlementLedger) Result(matchID, oddsID uint32, outcome string, placed time.Time) BetResult {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	ms := EpochMillis(placed)
+	for _, p := range l.matches[matchID] {
+		if p.contains(ms) {
+			return ResultVoid
+		}
+	}
+
+	mk, ok := l.markets[marketKey{matchID, oddsID}]
+	if !ok {
+		return ResultPending
+	}
+	for _, p := range mk.cancels {
+		if p.contains(ms) {
+			return ResultVoid
+		}
+	}
+
+	won, ok := mk.outcomes[outcome]
+	switch {
+	case !ok:
+		return ResultPending
+	case won:
+		return ResultWon
+	}
+	return ResultLost
+}
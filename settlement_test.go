@@ -0,0 +1,129 @@
+package liveodds
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+)
+
+func applyFixtures(ledger *SettlementLedger, fixtures ...string) {
+	for _, fixture := range fixtures {
+		feed := LoadXMLFixture(fixture)
+		ledger.Apply(&feed)
+	}
+}
+
+func TestSettlementClearAndRollback(t *testing.T) {
+	placed := time.Date(2013, time.November, 1, 17, 0, 0, 0, time.UTC)
+
+	ledger := NewSettlementLedger()
+	before := ledger.Result(793862, 78655, "2", placed)
+	applyFixtures(ledger, "fixtures/clearbet.xml")
+	home := ledger.Result(793862, 78655, "1", placed)
+	away := ledger.Result(793862, 78655, "2", placed)
+	other := ledger.Result(793862, 1, "1", placed)
+	applyFixtures(ledger, "fixtures/rollback.xml")
+	rolled := ledger.Result(793862, 78655, "2", placed)
+
+	var xmlTests = []xmlTest{
+		{before, ResultPending},
+		{home, ResultLost},
+		{away, ResultWon},
+		{other, ResultPending},
+		{rolled, ResultPending},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestSettlementClearAndRollback", tt.expected, tt.n)
+		}
+	}
+}
+
+func TestSettlementClearWithoutOutcome(t *testing.T) {
+	msg := `<BetradarLiveOdds status="clearbet" timestamp="1383323478000">
+    <Match matchid="1" msgnr="1">
+        <Odds id="10" type="3w" typeid="2">
+            <OddsField type="1" outcome="1"/>
+            <OddsField type="x" outcome="0"/>
+            <OddsField type="2"/>
+        </Odds>
+    </Match>
+</BetradarLiveOdds>`
+	placed := time.Date(2013, time.November, 1, 17, 0, 0, 0, time.UTC)
+
+	feed := BetRadarLiveOdds{}
+	check(xml.Unmarshal([]byte(msg), &feed))
+	ledger := NewSettlementLedger()
+	ledger.Apply(&feed)
+
+	var xmlTests = []xmlTest{
+		{ledger.Result(1, 10, "1", placed), ResultWon},
+		{ledger.Result(1, 10, "x", placed), ResultLost},
+		{ledger.Result(1, 10, "2", placed), ResultPending},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestSettlementClearWithoutOutcome", tt.expected, tt.n)
+		}
+	}
+}
+
+func TestSettlementCancelWithPeriod(t *testing.T) {
+	start := time.Unix(0, 1199435902000*int64(time.Millisecond))
+	end := time.Unix(0, 1199436022222*int64(time.Millisecond))
+
+	ledger := NewSettlementLedger()
+	applyFixtures(ledger, "fixtures/cancelbet_with_period.xml")
+
+	var xmlTests = []xmlTest{
+		{ledger.Result(661373, 13792, "1", start.Add(-time.Second)), ResultPending},
+		{ledger.Result(661373, 13792, "1", start), ResultVoid},
+		{ledger.Result(661373, 13792, "x", start.Add(time.Minute)), ResultVoid},
+		{ledger.Result(661373, 13792, "2", end), ResultVoid},
+		{ledger.Result(661373, 13792, "2", end.Add(time.Millisecond)), ResultPending},
+		{ledger.Result(661373, 1, "1", start), ResultPending},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestSettlementCancelWithPeriod", tt.expected, tt.n)
+		}
+	}
+}
+
+func TestSettlementUndoCancel(t *testing.T) {
+	placed := time.Date(2008, time.January, 4, 8, 40, 0, 0, time.UTC)
+
+	ledger := NewSettlementLedger()
+	applyFixtures(ledger, "fixtures/cancelbet.xml")
+	voided := ledger.Result(661373, 13792, "1", placed)
+	applyFixtures(ledger, "fixtures/undocancelbet.xml")
+	restored := ledger.Result(661373, 13792, "1", placed)
+
+	// a cancelbet without odds voids the whole match
+	ledger.Apply(&BetRadarLiveOdds{
+		Status:    "cancelbet",
+		StartTime: placed.Add(-time.Minute).UnixNano() / int64(time.Millisecond),
+		Matches:   []Match{{MatchID: 661373}},
+	})
+	match := ledger.Result(661373, 1, "1", placed)
+	after := ledger.Result(661373, 13792, "1", placed.Add(-time.Hour))
+	ledger.Apply(&BetRadarLiveOdds{Status: "undocancelbet", Matches: []Match{{MatchID: 661373}}})
+	undone := ledger.Result(661373, 1, "1", placed)
+
+	var xmlTests = []xmlTest{
+		{voided, ResultVoid},
+		{restored, ResultPending},
+		{match, ResultVoid},
+		{after, ResultPending},
+		{undone, ResultPending},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestSettlementUndoCancel", tt.expected, tt.n)
+		}
+	}
+}
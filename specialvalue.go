@@ -0,0 +1,325 @@
+// Copyright 2013 Oscar Campos <oscar.campos@member.fsf.org>
+// See LICENSE file for details.
+
+package liveodds
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrDifferentMarkets is returned when comparing odds of different markets
+var ErrDifferentMarkets = errors.New("liveodds: odds of different markets")
+
+// compositeSeparator separates the parts of a Composite special value
+const compositeSeparator = "|"
+
+// SpecialValue is a parsed SpecialOddsValue, it is one of Line, ScoreState,
+// Handicap, AsianHandicap or Composite
+type SpecialValue interface {
+	// String returns the value in the feed format
+	String() string
+	// Compare returns -1, 0 or 1 when the value is lower, equal or higher
+	// than other, ok is false when other is not of the same kind
+	Compare(other SpecialValue) (cmp int, ok bool)
+}
+
+// Line is a total line like "2.5" in fixed point, with the same scale as
+// Price so 2.5 is 25000
+type Line int64
+
+// ScoreState is the score of the match when the odds were offered, like
+// "1:0" in a next goal market
+type ScoreState struct {
+	Home int
+	Away int
+}
+
+// Handicap is the goals given to each team like "0:1"
+type Handicap struct {
+	Home int
+	Away int
+}
+
+// AsianHandicap is the goals given to the home team in an asian handicap
+// market, with the same scale as Line so -1.5 is -15000. The feed writes it
+// as the handicap of the home team like "-1.5" or as the goals given to each
+// team like "0:0.25", which is -0.25. It is always a multiple of a quarter
+// of a goal, the quarter lines split the stake between the two nearest
+// half lines.
+type AsianHandicap int64
+
+// asianQuarter is a quarter of a goal in the scale of an AsianHandicap
+const asianQuarter = PriceScale / 4
+
+// Composite is a special value made of several values, like "0:0|2.5"
+type Composite []SpecialValue
+
+// ParseSpecialValue parses a SpecialOddsValue of a market of the given kind.
+// With SpecialNone the kind is guessed from the format, "x:y" values are
+// parsed as a ScoreState, or as an AsianHandicap when they have decimals,
+// and numbers as a Line, as are the parts of a Composite. An empty string is a nil SpecialValue.
+func ParseSpecialValue(s string, kind SpecialKind) (SpecialValue, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+
+	if strings.Contains(s, compositeSeparator) {
+		var composite Composite
+		for _, part := range strings.Split(s, compositeSeparator) {
+			v, err := ParseSpecialValue(part, SpecialNone)
+			if err != nil || v == nil {
+				return nil, fmt.Errorf("%w %q", ErrInvalidSpecialValue, s)
+			}
+			composite = append(composite, v)
+		}
+		return composite, nil
+	}
+
+	if kind == SpecialNone {
+		kind = SpecialLine
+		if strings.Contains(s, ":") {
+			kind = SpecialScore
+			if strings.Contains(s, ".") {
+				kind = SpecialAsianHandicap
+			}
+		}
+	}
+
+	switch kind {
+	case SpecialLine:
+		l, err := parseLine(s)
+		if err != nil {
+			return nil, err
+		}
+		return l, nil
+	case SpecialAsianHandicap:
+		h, err := parseAsianHandicap(s)
+		if err != nil {
+			return nil, err
+		}
+		return h, nil
+	case SpecialScore, SpecialHandicap:
+		score, err := ParseScoreLine(s)
+		if err != nil || !score.Known {
+			return nil, fmt.Errorf("%w %q", ErrInvalidSpecialValue, s)
+		}
+		if kind == SpecialHandicap {
+			return Handicap{score.Home, score.Away}, nil
+		}
+		return ScoreState{score.Home, score.Away}, nil
+	}
+	return nil, fmt.Errorf("%w %q", ErrInvalidSpecialValue, s)
+}
+
+func parseLine(s string) (Line, error) {
+	abs, negative := s, false
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		abs, negative = s[1:], s[0] == '-'
+	}
+	p, err := ParsePrice(abs)
+	if err != nil {
+		return 0, fmt.Errorf("%w %q", ErrInvalidSpecialValue, s)
+	}
+	if negative {
+		return Line(-p), nil
+	}
+	return Line(p), nil
+}
+
+func parseAsianHandicap(s string) (AsianHandicap, error) {
+	var h Line
+	if i := strings.IndexByte(s, ':'); i >= 0 {
+		home, err := parseLine(strings.TrimSpace(s[:i]))
+		if err != nil {
+			return 0, fmt.Errorf("%w %q", ErrInvalidSpecialValue, s)
+		}
+		away, err := parseLine(strings.TrimSpace(s[i+1:]))
+		if err != nil {
+			return 0, fmt.Errorf("%w %q", ErrInvalidSpecialValue, s)
+		}
+		h = home - away
+	} else {
+		var err error
+		if h, err = parseLine(s); err != nil {
+			return 0, err
+		}
+	}
+	if h%asianQuarter != 0 {
+		return 0, fmt.Errorf("%w %q", ErrInvalidSpecialValue, s)
+	}
+	return AsianHandicap(h), nil
+}
+
+// String returns the line without trailing zeros, like "2.5" or "-1"
+func (l Line) String() string {
+	sign := ""
+	if l < 0 {
+		sign, l = "-", -l
+	}
+	s := fmt.Sprintf("%d.%04d", l/PriceScale, l%PriceScale)
+	return sign + strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+}
+
+// Float64 returns the line as a float, only for display or charts
+func (l Line) Float64() float64 {
+	return float64(l) / PriceScale
+}
+
+// Compare orders the lines by value
+func (l Line) Compare(other SpecialValue) (int, bool) {
+	o, ok := other.(Line)
+	if !ok {
+		return 0, false
+	}
+	return compareInts(int64(l), int64(o)), true
+}
+
+func (s ScoreState) String() string {
+	return fmt.Sprintf("%d:%d", s.Home, s.Away)
+}
+
+// Compare orders the score states by the number of goals scored and then by
+// the goals of the home team
+func (s ScoreState) Compare(other SpecialValue) (int, bool) {
+	o, ok := other.(ScoreState)
+	if !ok {
+		return 0, false
+	}
+	if cmp := compareInts(int64(s.Home+s.Away), int64(o.Home+o.Away)); cmp != 0 {
+		return cmp, true
+	}
+	return compareInts(int64(s.Home), int64(o.Home)), true
+}
+
+func (h Handicap) String() string {
+	return fmt.Sprintf("%d:%d", h.Home, h.Away)
+}
+
+// Goals returns the advantage of the home team, negative when the handicap
+// favours the away team
+func (h Handicap) Goals() int {
+	return h.Home - h.Away
+}
+
+// Compare orders the handicaps by the advantage of the home team
+func (h Handicap) Compare(other SpecialValue) (int, bool) {
+	o, ok := other.(Handicap)
+	if !ok {
+		return 0, false
+	}
+	return compareInts(int64(h.Goals()), int64(o.Goals())), true
+}
+
+// String returns the handicap of the home team, like "-0.25" for "0:0.25"
+func (h AsianHandicap) String() string {
+	return Line(h).String()
+}
+
+// Float64 returns the handicap as a float, only for display or charts
+func (h AsianHandicap) Float64() float64 {
+	return float64(h) / PriceScale
+}
+
+// Quarter reports whether the handicap is a quarter line like -0.25 or 1.75
+func (h AsianHandicap) Quarter() bool {
+	return h%(2*asianQuarter) != 0
+}
+
+// Split returns the two half lines the stake of a quarter line is split
+// between, -0.25 is 0 and -0.5. Any other handicap is returned twice.
+func (h AsianHandicap) Split() (low, high AsianHandicap) {
+	if !h.Quarter() {
+		return h, h
+	}
+	return h - asianQuarter, h + asianQuarter
+}
+
+// Compare orders the handicaps by the goals given to the home team
+func (h AsianHandicap) Compare(other SpecialValue) (int, bool) {
+	o, ok := other.(AsianHandicap)
+	if !ok {
+		return 0, false
+	}
+	return compareInts(int64(h), int64(o)), true
+}
+
+func (c Composite) String() string {
+	parts := make([]string, len(c))
+	for i, v := range c {
+		parts[i] = v.String()
+	}
+	return strings.Join(parts, compositeSeparator)
+}
+
+// Compare orders the composites part by part, they are of the same kind only
+// when all their parts are
+func (c Composite) Compare(other SpecialValue) (int, bool) {
+	o, ok := other.(Composite)
+	if !ok || len(c) != len(o) {
+		return 0, false
+	}
+
+	result := 0
+	for i := range c {
+		cmp, ok := c[i].Compare(o[i])
+		if !ok {
+			return 0, false
+		}
+		if result == 0 {
+			result = cmp
+		}
+	}
+	return result, true
+}
+
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// SpecialValue parses the SpecialOddsValue of odd as the kind of its market,
+// it is nil for markets that do not use it
+func (c *MarketCatalogue) SpecialValue(odd *Odd) (SpecialValue, error) {
+	market, ok := c.Market(odd)
+	if ok && market.Special == SpecialNone {
+		return nil, nil
+	}
+	return ParseSpecialValue(odd.SpecialOddsValue, market.Special)
+}
+
+// Compare orders two odds of the same market by their special values, like
+// two totals with lines 2.5 and 3.5
+func (c *MarketCatalogue) Compare(a, b *Odd) (int, error) {
+	if a.TypeID != b.TypeID || a.SubType != b.SubType {
+		return 0, fmt.Errorf("%w typeid %d/%d and %d/%d", ErrDifferentMarkets, a.TypeID, a.SubType, b.TypeID, b.SubType)
+	}
+
+	va, err := c.SpecialValue(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := c.SpecialValue(b)
+	if err != nil {
+		return 0, err
+	}
+	if va == nil || vb == nil {
+		if va == nil && vb == nil {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("%w %q and %q", ErrInvalidSpecialValue, a.SpecialOddsValue, b.SpecialOddsValue)
+	}
+
+	cmp, ok := va.Compare(vb)
+	if !ok {
+		return 0, fmt.Errorf("%w %q and %q", ErrInvalidSpecialValue, a.SpecialOddsValue, b.SpecialOddsValue)
+	}
+	return cmp, nil
+}
@@ -0,0 +1,165 @@
+package liveodds
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseSpecialValue(t *testing.T) {
+	parse := func(s string, kind SpecialKind) SpecialValue {
+		v, err := ParseSpecialValue(s, kind)
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		return v
+	}
+
+	composite := parse("0:0|2.5", SpecialNone).(Composite)
+	var xmlTests = []xmlTest{
+		{parse("2.5", SpecialLine), Line(25000)},
+		{parse("-0.25", SpecialLine), Line(-2500)},
+		{parse("2:0", SpecialScore), ScoreState{2, 0}},
+		{parse("0:1", SpecialHandicap), Handicap{0, 1}},
+		{parse("0:1", SpecialHandicap).(Handicap).Goals(), -1},
+		{parse("1:1", SpecialNone), ScoreState{1, 1}},
+		{parse("3", SpecialNone), Line(30000)},
+		{parse("", SpecialLine), nil},
+		{len(composite), 2},
+		{composite[0], ScoreState{0, 0}},
+		{composite[1], Line(25000)},
+		{composite.String(), "0:0|2.5"},
+		{Line(25000).String(), "2.5"},
+		{Line(-10000).String(), "-1"},
+		{Line(-2500).String(), "-0.25"},
+		{Line(22500).Float64(), 2.25},
+		{Handicap{1, 0}.String(), "1:0"},
+		{parse("0:0.25", SpecialAsianHandicap), AsianHandicap(-2500)},
+		{parse("-1.5", SpecialAsianHandicap), AsianHandicap(-15000)},
+		{parse("0.75", SpecialAsianHandicap), AsianHandicap(7500)},
+		{parse("1:0", SpecialAsianHandicap), AsianHandicap(10000)},
+		{parse("0.5:0", SpecialNone), AsianHandicap(5000)},
+		{AsianHandicap(-2500).String(), "-0.25"},
+		{AsianHandicap(-2500).Quarter(), true},
+		{AsianHandicap(-15000).Quarter(), false},
+		{SpecialAsianHandicap.String(), "asianhandicap"},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestParseSpecialValue", tt.expected, tt.n)
+		}
+	}
+
+	for _, s := range []string{"abc", "2.5.5", "-", "-:-", "1:x", "2.5|", "1.23456"} {
+		if _, err := ParseSpecialValue(s, SpecialNone); !errors.Is(err, ErrInvalidSpecialValue) {
+			t.Errorf(failed_msg, "TestParseSpecialValue "+s, ErrInvalidSpecialValue, err)
+		}
+	}
+
+	for _, s := range []string{"0.3", "0:", ":0.25", "0:0.1", "1.", "x:0.5"} {
+		if _, err := ParseSpecialValue(s, SpecialAsianHandicap); !errors.Is(err, ErrInvalidSpecialValue) {
+			t.Errorf(failed_msg, "TestParseSpecialValue "+s, ErrInvalidSpecialValue, err)
+		}
+	}
+}
+
+func TestAsianHandicapFixture(t *testing.T) {
+	catalogue := NewMarketCatalogue(Market{TypeID: 16, Type: "ah", Label: "Asian handicap",
+		Outcomes: []string{"1", "2"}, Special: SpecialAsianHandicap})
+	feed := LoadXMLFixture("fixtures/asianhandicap.xml")
+	odds := feed.Matches[0].Odds
+
+	var values []SpecialValue
+	for i := range odds {
+		check(catalogue.Validate(&odds[i]))
+		v, err := catalogue.SpecialValue(&odds[i])
+		check(err)
+		values = append(values, v)
+	}
+	low, high := values[2].(AsianHandicap).Split()
+	cmp, err := catalogue.Compare(&odds[0], &odds[1])
+	check(err)
+
+	var xmlTests = []xmlTest{
+		{values[0], AsianHandicap(-2500)},
+		{values[1], AsianHandicap(-15000)},
+		{values[2], AsianHandicap(-7500)},
+		{low, AsianHandicap(-10000)},
+		{high, AsianHandicap(-5000)},
+		{cmp, 1},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestAsianHandicapFixture", tt.expected, tt.n)
+		}
+	}
+}
+
+func TestSpecialValueCompare(t *testing.T) {
+	compare := func(a, b SpecialValue) int {
+		cmp, ok := a.Compare(b)
+		if !ok {
+			return -2
+		}
+		return cmp
+	}
+
+	var xmlTests = []xmlTest{
+		{compare(Line(25000), Line(35000)), -1},
+		{compare(Line(25000), Line(25000)), 0},
+		{compare(ScoreState{1, 0}, ScoreState{0, 0}), 1},
+		{compare(ScoreState{0, 1}, ScoreState{1, 0}), -1},
+		{compare(Handicap{0, 1}, Handicap{0, 2}), 1},
+		{compare(Composite{ScoreState{0, 0}, Line(25000)}, Composite{ScoreState{0, 0}, Line(15000)}), 1},
+		{compare(Composite{ScoreState{0, 0}}, Composite{Line(15000)}), -2},
+		{compare(Line(25000), ScoreState{0, 0}), -2},
+		{compare(ScoreState{0, 0}, Handicap{0, 0}), -2},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestSpecialValueCompare", tt.expected, tt.n)
+		}
+	}
+}
+
+func TestMarketCatalogueCompare(t *testing.T) {
+	catalogue := NewMarketCatalogue()
+	change := LoadXMLFixture("fixtures/change.xml")
+	nextGoal := change.Matches[0].Odds[0]
+	total := change.Matches[0].Odds[1]
+
+	higher := total
+	higher.SpecialOddsValue = "3.5"
+	scored := nextGoal
+	scored.SpecialOddsValue = "1:0"
+	kickOff := change.Matches[0].Odds[4]
+
+	value, err := catalogue.SpecialValue(&total)
+	check(err)
+	none, err := catalogue.SpecialValue(&kickOff)
+	check(err)
+	cmp := func(a, b *Odd) int {
+		cmp, err := catalogue.Compare(a, b)
+		check(err)
+		return cmp
+	}
+	_, differentErr := catalogue.Compare(&total, &nextGoal)
+
+	var xmlTests = []xmlTest{
+		{value, Line(25000)},
+		{none, nil},
+		{cmp(&total, &higher), -1},
+		{cmp(&higher, &total), 1},
+		{cmp(&scored, &nextGoal), 1},
+		{cmp(&kickOff, &kickOff), 0},
+		{errors.Is(differentErr, ErrDifferentMarkets), true},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestMarketCatalogueCompare", tt.expected, tt.n)
+		}
+	}
+}
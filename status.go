@@ -0,0 +1,74 @@
+// Copyright 2013 Oscar Campos <oscar.campos@member.fsf.org>
+// See LICENSE file for details.
+
+package liveodds
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownStatus is returned when a status attribute is not one of the
+// statuses defined by the BetRadar LiveOdds protocol
+var ErrUnknownStatus = errors.New("liveodds: unknown message status")
+
+// MessageStatus is the kind of a BetRadarLiveOdds message as set in its
+// status attribute
+type MessageStatus uint8
+
+const (
+	StatusUnknown MessageStatus = iota
+	StatusAlive
+	StatusChange
+	StatusBetStart
+	StatusBetStop
+	StatusClearBet
+	StatusCancelBet
+	StatusUndoCancelBet
+	StatusRollback
+	StatusScore
+	StatusTranslation
+	StatusMeta
+)
+
+var statusNames = [...]string{
+	StatusUnknown:       "unknown",
+	StatusAlive:         "alive",
+	StatusChange:        "change",
+	StatusBetStart:      "betstart",
+	StatusBetStop:       "betstop",
+	StatusClearBet:      "clearbet",
+	StatusCancelBet:     "cancelbet",
+	StatusUndoCancelBet: "undocancelbet",
+	StatusRollback:      "rollback",
+	StatusScore:         "score",
+	StatusTranslation:   "translation",
+	StatusMeta:          "meta",
+}
+
+// ParseMessageStatus returns the MessageStatus for the given status
+// attribute value, values that are not part of the protocol (typos
+// included) return StatusUnknown and an error wrapping ErrUnknownStatus
+func ParseMessageStatus(status string) (MessageStatus, error) {
+	for s, name := range statusNames {
+		if MessageStatus(s) != StatusUnknown && name == status {
+			return MessageStatus(s), nil
+		}
+	}
+	return StatusUnknown, fmt.Errorf("%w %q", ErrUnknownStatus, status)
+}
+
+// String returns the status as it is written in the status attribute
+func (s MessageStatus) String() string {
+	if int(s) < len(statusNames) {
+		return statusNames[s]
+	}
+	return fmt.Sprintf("MessageStatus(%d)", s)
+}
+
+// Kind returns the typed Status of the message, StatusUnknown is returned
+// when the status attribute is not a known one
+func (t *BetRadarLiveOdds) Kind() MessageStatus {
+	kind, _ := ParseMessageStatus(t.Status)
+	return kind
+}
@@ -0,0 +1,50 @@
+package liveodds
+
+import (
+	"errors"
+	"testing"
+)
+
+func fixtureKind(fixture string) MessageStatus {
+	feed := LoadXMLFixture(fixture)
+	return feed.Kind()
+}
+
+func TestFixturesKind(t *testing.T) {
+	var xmlTests = []xmlTest{
+		{fixtureKind("fixtures/alive.xml"), StatusAlive},
+		{fixtureKind("fixtures/change.xml"), StatusChange},
+		{fixtureKind("fixtures/betstart.xml"), StatusBetStart},
+		{fixtureKind("fixtures/betstop.xml"), StatusBetStop},
+		{fixtureKind("fixtures/clearbet.xml"), StatusClearBet},
+		{fixtureKind("fixtures/cancelbet.xml"), StatusCancelBet},
+		{fixtureKind("fixtures/undocancelbet.xml"), StatusUndoCancelBet},
+		{fixtureKind("fixtures/rollback.xml"), StatusRollback},
+		{fixtureKind("fixtures/score.xml"), StatusScore},
+		{fixtureKind("fixtures/translation.xml"), StatusTranslation},
+		{fixtureKind("fixtures/registerreply.xml"), StatusMeta},
+		{(&BetRadarLiveOdds{Status: "alvie"}).Kind(), StatusUnknown},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestFixturesKind", tt.expected, tt.n)
+		}
+	}
+}
+
+func TestParseMessageStatus(t *testing.T) {
+	for s := StatusAlive; s <= StatusMeta; s++ {
+		status, err := ParseMessageStatus(s.String())
+		if err != nil || status != s {
+			t.Errorf(failed_msg, "TestParseMessageStatus", s, status)
+		}
+	}
+
+	for _, typo := range []string{"", "Alive", "bet_start", "unknown"} {
+		status, err := ParseMessageStatus(typo)
+		if status != StatusUnknown || !errors.Is(err, ErrUnknownStatus) {
+			t.Errorf(failed_msg, "TestParseMessageStatus", ErrUnknownStatus, err)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2013 Oscar Campos <oscar.campos@member.fsf.org>
+// See LICENSE file for details.
+
+package liveodds
+
+import (
+	"sync"
+)
+
+// DefaultLanguage is the language used when a name is not translated to the
+// requested one
+const DefaultLanguage = "en"
+
+// oddsTypeNames are the names of an odds type and its outcomes by language
+type oddsTypeNames struct {
+	names    map[string]string            // lang -> name
+	outcomes map[string]map[string]string // outcome type -> lang -> name
+}
+
+// Translations keeps the names of the odds types and their outcomes that
+// BetRadar sends in translation messages:
+//
+//	translations := NewTranslations()
+//	translations.Apply(msg) // for every message of the feed
+//	name, ok := translations.OutcomeName(6, "x", "de")
+//
+// Every translation message is merged with the previous ones and a name that
+// is not translated to the requested language falls back to English.
+// It is safe to use from several goroutines.
+type Translations struct {
+	mu      sync.RWMutex
+	types   map[uint16]*oddsTypeNames
+	typeIDs map[string]uint16 // type -> typeid
+}
+
+// NewTranslations creates an empty Translations
+func NewTranslations() *Translations {
+	return &Translations{
+		types:   make(map[uint16]*oddsTypeNames),
+		typeIDs: make(map[string]uint16),
+	}
+}
+
+// Apply merges the names of a translation message, any other message is
+// ignored
+func (t *Translations) Apply(msg *BetRadarLiveOdds) {
+	if msg.Kind() != StatusTranslation {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, oddsType := range msg.OddsType {
+		names, ok := t.types[oddsType.TypeID]
+		if !ok {
+			names = &oddsTypeNames{
+				names:    make(map[string]string),
+				outcomes: make(map[string]map[string]string),
+			}
+			t.types[oddsType.TypeID] = names
+		}
+		if oddsType.Type != "" {
+			t.typeIDs[oddsType.Type] = oddsType.TypeID
+		}
+
+		mergeNames(names.names, oddsType.Name)
+		for _, field := range oddsType.OddsField {
+			outcome, ok := names.outcomes[field.Type]
+			if !ok {
+				outcome = make(map[string]string)
+				names.outcomes[field.Type] = outcome
+			}
+			mergeNames(outcome, field.Name)
+		}
+	}
+}
+
+func mergeNames(names map[string]string, translated []Name) {
+	for _, name := range translated {
+		names[name.Lang] = name.Value
+	}
+}
+
+// TypeID returns the typeid of the given odds type, like 2 for "3w"
+func (t *Translations) TypeID(typ string) (uint16, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	id, ok := t.typeIDs[typ]
+	return id, ok
+}
+
+// OddsTypeName returns the name of the odds type in the given language
+func (t *Translations) OddsTypeName(typeID uint16, lang string) (string, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	names, ok := t.types[typeID]
+	if !ok {
+		return "", false
+	}
+	return translate(names.names, lang)
+}
+
+// OutcomeName returns the name of the outcome of the odds type in the given
+// language, outcome is the type of the OddsField like "1", "x" or "2"
+func (t *Translations) OutcomeName(typeID uint16, outcome, lang string) (string, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	names, ok := t.types[typeID]
+	if !ok {
+		return "", false
+	}
+	return translate(names.outcomes[outcome], lang)
+}
+
+// translate returns the name in lang or in DefaultLanguage if there is no
+// name in lang
+func translate(names map[string]string, lang string) (string, bool) {
+	if name, ok := names[lang]; ok {
+		return name, true
+	}
+	name, ok := names[DefaultLanguage]
+	return name, ok
+}
@@ -0,0 +1,66 @@
+package liveodds
+
+import (
+	"testing"
+)
+
+func TestTranslations(t *testing.T) {
+	translations := NewTranslations()
+	english := LoadXMLFixture("fixtures/translation.xml")
+	german := LoadXMLFixture("fixtures/translation_de.xml")
+	change := LoadXMLFixture("fixtures/change.xml")
+	translations.Apply(&english)
+	translations.Apply(&german)
+	translations.Apply(&change)
+
+	name := func(typeID uint16, outcome, lang string) string {
+		if outcome == "" {
+			name, _ := translations.OddsTypeName(typeID, lang)
+			return name
+		}
+		name, _ := translations.OutcomeName(typeID, outcome, lang)
+		return name
+	}
+	_, unknownType := translations.OddsTypeName(1, "en")
+	_, unknownOutcome := translations.OutcomeName(2, "o", "de")
+	handicap, _ := translations.TypeID("hc")
+
+	var xmlTests = []xmlTest{
+		{name(2, "", "de"), "Dreiweg"},
+		{name(2, "", "en"), "3way"},
+		{name(2, "1", "de"), "Heim"},
+		{name(2, "x", "de"), "Unentschieden"},
+		{name(2, "2", "de"), "2"}, // falls back to english
+		{name(4, "", "fr"), "Handicap"},
+		{name(6, "", "de"), "Nächstes Tor"},
+		{name(6, "x", "en"), "No goal"},
+		{name(6, "x", "de"), "Kein Tor"},
+		{unknownType, false},
+		{unknownOutcome, false},
+		{handicap, uint16(4)},
+	}
+
+	for _, tt := range xmlTests {
+		if tt.n != tt.expected {
+			t.Errorf(failed_msg, "TestTranslations", tt.expected, tt.n)
+		}
+	}
+}
+
+func TestTranslationsUpdate(t *testing.T) {
+	translations := NewTranslations()
+	english := LoadXMLFixture("fixtures/translation.xml")
+	translations.Apply(&english)
+
+	update := LoadXMLFixture("fixtures/translation.xml")
+	update.OddsType = update.OddsType[:1]
+	update.OddsType[0].Name = []Name{{Value: "Three way", Lang: "en"}}
+	update.OddsType[0].OddsField = nil
+	translations.Apply(&update)
+
+	typeName, _ := translations.OddsTypeName(2, "en")
+	outcome, _ := translations.OutcomeName(2, "x", "en")
+	if typeName != "Three way" || outcome != "x" {
+		t.Errorf(failed_msg, "TestTranslationsUpdate", "Three way and x", typeName+" and "+outcome)
+	}
+}